@@ -1,26 +1,48 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"test-task/internal/app"
 	"test-task/internal/config"
-	"test-task/internal/handlers"
+	"test-task/internal/metrics"
 	"test-task/internal/repositories"
-	"test-task/internal/router"
-	"test-task/internal/services"
+	"test-task/internal/tracing"
+	"time"
 )
 
 func main() {
 
 	cfg := config.Get()
 
-	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString)
+	if cfg.LogFormat == config.JsonLogFormat {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+	if cfg.Mode == config.DebugMode {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	shutdownTracing, err := setupTracing(cfg)
+	if err != nil {
+		log.Fatalf("error setup tracing: %v", err)
+		return
+	}
+
+	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString, cfg.DbReplicas...)
 	if err != nil {
 		log.Fatalf("error create dbContext: %v", err)
 		return
 	}
-	defer dbContext.Close()
 
 	err = dbContext.Migrate()
 	if err != nil {
@@ -29,15 +51,99 @@ func main() {
 	}
 	log.Infof("database migration complete")
 
-	walletRepository := repositories.NewWalletsRepository(dbContext.DB)
-	walletService := services.NewWalletsService(walletRepository)
-	defer walletService.Close()
-	walletHandler := handlers.NewWalletHandler(walletService)
+	if err := registerDbMetrics(dbContext); err != nil {
+		log.Fatalf("error register database metrics: %v", err)
+		return
+	}
 
 	gin.SetMode(cfg.Mode)
 	ginEngine := gin.New()
 
-	router.Setup(ginEngine, walletHandler)
+	application, err := app.New(cfg, dbContext, ginEngine)
+	if err != nil {
+		log.Fatalf("error setup app: %v", err)
+		return
+	}
+	listenCtx, stopListening := context.WithCancel(context.Background())
+	go func() {
+		if err := application.Wallets.Listen(listenCtx, cfg.DbConnectionString); err != nil {
+			log.Errorf("error listen for wallet changes: %v", err)
+		}
+	}()
+
+	server := &http.Server{Addr: ":" + strconv.Itoa(cfg.Port), Handler: ginEngine}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	application.Health.SetReady(true)
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	failed := false
+	select {
+	case err := <-serverErr:
+		// there is nothing to drain when the server could not serve
+		failed = !errors.Is(err, http.ErrServerClosed)
+		log.Errorf("server stopped: %v", err)
+	case <-signalCtx.Done():
+		log.Infof("shutting down")
+	}
+
+	application.Health.SetReady(false)
 
-	log.Errorf(ginEngine.Run(":" + strconv.Itoa(cfg.Port)).Error())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if !failed {
+		time.Sleep(cfg.ShutdownDelay)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("error drain connections: %v", err)
+		}
+	}
+
+	application.Close()
+	stopListening()
+	if err := dbContext.Close(); err != nil {
+		log.Errorf("error close dbContext: %v", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Errorf("error flush spans: %v", err)
+	}
+	if failed {
+		cancel()
+		os.Exit(1)
+	}
+	log.Infof("shutdown complete")
+}
+
+func setupTracing(cfg *config.Config) (func(context.Context) error, error) {
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case config.StdoutTracing:
+		exporter, err = stdouttrace.New()
+	case config.OtlpTracing:
+		exporter, err = otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tracing.Setup(exporter, cfg.TracingServiceName)
+}
+
+func registerDbMetrics(dbContext *repositories.DbContext) error {
+	if err := metrics.RegisterDB("primary", dbContext.DB.DB); err != nil {
+		return err
+	}
+	for i, replica := range dbContext.Replicas {
+		if err := metrics.RegisterDB("replica_"+strconv.Itoa(i), replica.DB); err != nil {
+			return err
+		}
+	}
+	return nil
 }
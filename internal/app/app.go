@@ -0,0 +1,97 @@
+// Package app wires the repositories, services and handlers from the config, shared by main and the integration tests.
+package app
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"test-task/internal/auth"
+	"test-task/internal/config"
+	"test-task/internal/entities"
+	"test-task/internal/handlers"
+	"test-task/internal/metrics"
+	"test-task/internal/ratelimit"
+	"test-task/internal/repositories"
+	"test-task/internal/router"
+	"test-task/internal/services"
+)
+
+type App struct {
+	Wallets *repositories.CachedWallets
+	Health  *handlers.HealthHandler
+
+	walletService *services.WalletsService
+}
+
+// New sets up the routes on engine, the caller owns dbContext and closes it after Close.
+func New(cfg *config.Config, dbContext *repositories.DbContext, engine *gin.Engine) (*App, error) {
+
+	jwtVerifier, err := newJwtVerifier(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("load jwt keys: %w", err)
+	}
+
+	walletRepository := repositories.NewCachedWalletsRepository(
+		repositories.NewWalletsRepository(dbContext.DB, dbContext.Replicas...), cfg.BalanceCacheStaleness())
+	fxRatesRepository := repositories.NewFxRatesRepository(dbContext.DB)
+	walletService := services.NewWalletsService(walletRepository, fxRatesRepository, newRateLimiter(cfg, dbContext),
+		services.Options{
+			FxRounding:                    cfg.FxRoundingMode,
+			HoldTTL:                       cfg.HoldDefaultTTL,
+			HoldExpiryInterval:            cfg.HoldExpiryInterval,
+			WithdrawalLimits:              cfg.WithdrawalLimitDefaults(),
+			IdempotencyKeyTTL:             cfg.IdempotencyKeyTTL,
+			IdempotencyKeyCleanupInterval: cfg.IdempotencyKeyCleanupInterval,
+			ReadRateLimit:                 entities.RateLimitPolicy{Rate: cfg.ReadRateLimit, Burst: cfg.ReadRateBurst},
+			WriteRateLimit:                entities.RateLimitPolicy{Rate: cfg.WriteRateLimit, Burst: cfg.WriteRateBurst},
+			RateLimitIdleTTL:              cfg.RateLimitIdleTTL,
+			RateLimitCleanupInterval:      cfg.RateLimitCleanupInterval,
+			WriteCoalescingWindow:         cfg.WriteCoalescingWindow,
+			WriteCoalescingMaxBatch:       cfg.WriteCoalescingMaxBatch,
+		})
+	fxRatesService := services.NewFxRatesService(fxRatesRepository)
+	walletHandler := handlers.NewWalletHandler(walletService)
+	apiKeysService := services.NewApiKeysService(repositories.NewApiKeysRepository(dbContext.DB), cfg.AdminApiKey)
+	adminHandler := handlers.NewAdminHandler(walletService, fxRatesService, apiKeysService)
+	authHandler := handlers.NewAuthHandler(apiKeysService, jwtVerifier)
+	healthHandler := handlers.NewHealthHandler(dbContext)
+
+	router.Setup(engine, walletHandler, adminHandler, healthHandler, authHandler, router.Options{
+		ReplicaMaxLag:         cfg.ReplicaMaxLag(),
+		ServiceName:           cfg.TracingServiceName,
+		RequireAuthentication: cfg.AuthEnabled,
+	})
+
+	return &App{Wallets: walletRepository, Health: healthHandler, walletService: walletService}, nil
+}
+
+// Close stops the background jobs of the services.
+func (a *App) Close() {
+	a.walletService.Close()
+}
+
+func newRateLimiter(cfg *config.Config, dbContext *repositories.DbContext) services.RateLimiter {
+	if cfg.RateLimiter == config.PostgresRateLimiter {
+		return repositories.NewRateLimitsRepository(dbContext.DB)
+	}
+	limiter := ratelimit.NewMemory(cfg.RateLimitMaxKeys)
+	if err := metrics.RegisterLimiterKeys(limiter.Len); err != nil {
+		log.Errorf("error register rate limiter metrics: %v", err)
+	}
+	return limiter
+}
+
+func newJwtVerifier(cfg *config.Config) (*auth.JwtVerifier, error) {
+
+	publicKeys, err := auth.LoadPublicKeys(cfg.JwtPublicKeyFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewJwtVerifier(auth.JwtOptions{
+		HmacSecret: cfg.JwtHmacSecret,
+		PublicKeys: publicKeys,
+		Issuer:     cfg.JwtIssuer,
+		Audience:   cfg.JwtAudience,
+	})
+}
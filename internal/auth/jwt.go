@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"slices"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const AdminRole = "admin"
+
+type JwtOptions struct {
+	// HmacSecret verifies HS* tokens when set.
+	HmacSecret string
+	// PublicKeys verify RS*, PS*, ES* and EdDSA tokens.
+	PublicKeys []crypto.PublicKey
+	Issuer     string
+	Audience   string
+}
+
+type claims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// JwtVerifier checks bearer tokens against locally configured keys, trying
+// every key of the token's algorithm family so keys can be rotated.
+type JwtVerifier struct {
+	parser *jwt.Parser
+	keys   map[string][]jwt.VerificationKey
+}
+
+func NewJwtVerifier(options JwtOptions) (*JwtVerifier, error) {
+
+	keys := make(map[string][]jwt.VerificationKey)
+	if options.HmacSecret != "" {
+		keys["HS"] = append(keys["HS"], []byte(options.HmacSecret))
+	}
+	for _, key := range options.PublicKeys {
+		switch key.(type) {
+		case *rsa.PublicKey:
+			keys["RS"] = append(keys["RS"], key)
+			keys["PS"] = append(keys["PS"], key)
+		case *ecdsa.PublicKey:
+			keys["ES"] = append(keys["ES"], key)
+		case ed25519.PublicKey:
+			keys["Ed"] = append(keys["Ed"], key)
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+
+	var methods []string
+	for _, method := range []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"} {
+		if len(keys[method[:2]]) > 0 {
+			methods = append(methods, method)
+		}
+	}
+
+	parserOptions := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if options.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(options.Issuer))
+	}
+	if options.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(options.Audience))
+	}
+
+	return &JwtVerifier{parser: jwt.NewParser(parserOptions...), keys: keys}, nil
+}
+
+func (v *JwtVerifier) Verify(token string) (entities.Principal, error) {
+
+	if len(v.keys) == 0 {
+		return entities.Principal{}, fmt.Errorf("%w: bearer tokens are not accepted", errs.Unauthorized)
+	}
+
+	var parsed claims
+	_, err := v.parser.ParseWithClaims(token, &parsed, func(token *jwt.Token) (any, error) {
+		return jwt.VerificationKeySet{Keys: v.keys[token.Method.Alg()[:2]]}, nil
+	})
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: %v", errs.Unauthorized, err)
+	}
+
+	if parsed.Subject == "" {
+		return entities.Principal{}, fmt.Errorf("%w: token has no subject", errs.Unauthorized)
+	}
+
+	return entities.Principal{Subject: parsed.Subject, Admin: slices.Contains(parsed.Roles, AdminRole)}, nil
+}
+
+// LoadPublicKeys reads PEM encoded PKIX public keys.
+func LoadPublicKeys(paths ...string) ([]crypto.PublicKey, error) {
+
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM data", path)
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	errs "test-task/internal/errors"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, subject string, roles []string, expiresIn time.Duration) string {
+	token, err := jwt.NewWithClaims(method, claims{
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+	}).SignedString(key)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestJwtVerifier_WithHmacSecret(t *testing.T) {
+
+	verifier, err := NewJwtVerifier(JwtOptions{HmacSecret: "secret"})
+	assert.NoError(t, err)
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte("secret"), "alice", nil, time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.False(t, principal.Admin)
+
+	principal, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte("secret"), "ops", []string{AdminRole}, time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, principal.Admin)
+
+	for _, token := range []string{
+		sign(t, jwt.SigningMethodHS256, []byte("other"), "alice", nil, time.Minute),
+		sign(t, jwt.SigningMethodHS256, []byte("secret"), "alice", nil, -time.Minute),
+		sign(t, jwt.SigningMethodHS256, []byte("secret"), "", nil, time.Minute),
+		"not-a-token",
+	} {
+		_, err = verifier.Verify(token)
+		assert.True(t, errors.Is(err, errs.Unauthorized), "token %s: %v", token, err)
+	}
+}
+
+func TestJwtVerifier_WithPublicKeyFile(t *testing.T) {
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	publicKeys, err := LoadPublicKeys(path)
+	assert.NoError(t, err)
+	verifier, err := NewJwtVerifier(JwtOptions{PublicKeys: publicKeys})
+	assert.NoError(t, err)
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, private, "alice", nil, time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+
+	// an HMAC token must not be accepted when only public keys are configured
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, der, "alice", nil, time.Minute))
+	assert.True(t, errors.Is(err, errs.Unauthorized))
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+	"test-task/internal/entities"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal entities.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the caller authenticated for the request, if authentication is on.
+func PrincipalFrom(ctx context.Context) (entities.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(entities.Principal)
+	return principal, ok
+}
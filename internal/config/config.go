@@ -3,9 +3,15 @@ package config
 import (
 	"errors"
 	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"os"
+	"strings"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+	"time"
 )
 
 const (
@@ -13,12 +19,117 @@ const (
 	ReleaseMode = "release"
 )
 
+const (
+	MemoryRateLimiter   = "memory"
+	PostgresRateLimiter = "postgres"
+)
+
+const (
+	JsonLogFormat = "json"
+	TextLogFormat = "text"
+)
+
+const (
+	NoTracing     = "none"
+	StdoutTracing = "stdout"
+	OtlpTracing   = "otlp"
+)
+
 type Config struct {
-	Port               int    `mapstructure:"PORT"`
-	Mode               string `mapstructure:"MODE"`
-	DbConnectionString string `mapstructure:"DB_CONNECTION_STRING"`
+	Port               int                `mapstructure:"PORT"`
+	Mode               string             `mapstructure:"MODE"`
+	LogFormat          string             `mapstructure:"LOG_FORMAT"`
+	ShutdownDelay      time.Duration      `mapstructure:"SHUTDOWN_DELAY"`
+	ShutdownTimeout    time.Duration      `mapstructure:"SHUTDOWN_TIMEOUT"`
+	DbConnectionString string             `mapstructure:"DB_CONNECTION_STRING"`
+	DbReplicas         []string           `mapstructure:"DB_REPLICA_CONNECTION_STRINGS"`
+	DbReplicaMaxLag    time.Duration      `mapstructure:"DB_REPLICA_MAX_LAG"`
+	FxRoundingMode     money.RoundingMode `mapstructure:"FX_ROUNDING_MODE"`
+	HoldDefaultTTL     time.Duration      `mapstructure:"HOLD_DEFAULT_TTL"`
+	HoldExpiryInterval time.Duration      `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+
+	IdempotencyKeyTTL             time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyKeyCleanupInterval time.Duration `mapstructure:"IDEMPOTENCY_KEY_CLEANUP_INTERVAL"`
+	RateLimiter                   string        `mapstructure:"RATE_LIMITER"`
+
+	AuthEnabled bool `mapstructure:"AUTH_ENABLED"`
+	// AdminApiKey is accepted as an admin API key, to issue the first keys with.
+	AdminApiKey string `mapstructure:"AUTH_ADMIN_API_KEY"`
+	// Bearer tokens are verified against the HMAC secret and the PEM public keys in these files.
+	JwtHmacSecret     string   `mapstructure:"AUTH_JWT_HMAC_SECRET"`
+	JwtPublicKeyFiles []string `mapstructure:"AUTH_JWT_PUBLIC_KEY_FILES"`
+	JwtIssuer         string   `mapstructure:"AUTH_JWT_ISSUER"`
+	JwtAudience       string   `mapstructure:"AUTH_JWT_AUDIENCE"`
+
+	TracingExporter    string `mapstructure:"TRACING_EXPORTER"`
+	TracingEndpoint    string `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName string `mapstructure:"TRACING_SERVICE_NAME"`
+
+	ReadRateLimit            float64       `mapstructure:"RATE_LIMIT_READ_RATE"`
+	ReadRateBurst            int           `mapstructure:"RATE_LIMIT_READ_BURST"`
+	WriteRateLimit           float64       `mapstructure:"RATE_LIMIT_WRITE_RATE"`
+	WriteRateBurst           int           `mapstructure:"RATE_LIMIT_WRITE_BURST"`
+	RateLimitIdleTTL         time.Duration `mapstructure:"RATE_LIMIT_IDLE_TTL"`
+	RateLimitCleanupInterval time.Duration `mapstructure:"RATE_LIMIT_CLEANUP_INTERVAL"`
+	RateLimitMaxKeys         int           `mapstructure:"RATE_LIMIT_MAX_KEYS"`
+
+	WriteCoalescingWindow   time.Duration `mapstructure:"WRITE_COALESCING_WINDOW"`
+	WriteCoalescingMaxBatch int           `mapstructure:"WRITE_COALESCING_MAX_BATCH"`
+
+	BalanceCacheEnabled bool          `mapstructure:"BALANCE_CACHE_ENABLED"`
+	BalanceCacheTTL     time.Duration `mapstructure:"BALANCE_CACHE_TTL"`
+
+	// Default withdrawal limits per currency as "USD:1000,EUR:900", a missing
+	// currency or a zero amount means no limit.
+	WithdrawalLimitPerOperation CurrencyAmounts `mapstructure:"WITHDRAWAL_LIMIT_PER_OPERATION"`
+	WithdrawalLimitDaily        CurrencyAmounts `mapstructure:"WITHDRAWAL_LIMIT_DAILY"`
+	WithdrawalLimitMonthly      CurrencyAmounts `mapstructure:"WITHDRAWAL_LIMIT_MONTHLY"`
+}
+
+// CurrencyAmounts maps currency codes to amounts in that currency.
+type CurrencyAmounts map[string]decimal.Decimal
+
+func (a *CurrencyAmounts) UnmarshalText(text []byte) error {
+
+	amounts := CurrencyAmounts{}
+
+	for _, entry := range strings.Split(string(text), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("invalid currency amount %q, expected CODE:AMOUNT", entry)
+		}
+
+		currency, err := money.ParseCurrency(strings.TrimSpace(code))
+		if err != nil {
+			return err
+		}
+
+		amount, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid %s amount %q: %w", currency.Code, value, err)
+		}
+		if amount.IsNegative() {
+			return fmt.Errorf("%s amount must not be negative", currency.Code)
+		}
+		if err := currency.Validate(amount); err != nil {
+			return err
+		}
+
+		amounts[currency.Code] = amount
+	}
+
+	*a = amounts
+	return nil
 }
 
+// minAdminApiKeyLength keeps a configured admin key as hard to guess as an issued one.
+const minAdminApiKeyLength = 32
+
 var configFile = "./configs/config.env"
 
 func Get() *Config {
@@ -42,13 +153,55 @@ func loadConfig(file string) (*Config, error) {
 
 	viper.SetDefault("PORT", 8080)
 	viper.SetDefault("MODE", ReleaseMode)
+	viper.SetDefault("LOG_FORMAT", JsonLogFormat)
+	// On SIGTERM /readyz fails for SHUTDOWN_DELAY so load balancers stop routing
+	// here, then in-flight requests get SHUTDOWN_TIMEOUT to finish. Together
+	// they must fit the grace period, 20s in docker-compose.yml.
+	viper.SetDefault("SHUTDOWN_DELAY", 4*time.Second)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
+	viper.SetDefault("DB_REPLICA_CONNECTION_STRINGS", "")
+	viper.SetDefault("DB_REPLICA_MAX_LAG", 5*time.Second)
+	viper.SetDefault("FX_ROUNDING_MODE", string(money.RoundHalfEven))
+	viper.SetDefault("HOLD_DEFAULT_TTL", 7*24*time.Hour)
+	viper.SetDefault("HOLD_EXPIRY_INTERVAL", time.Minute)
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	viper.SetDefault("IDEMPOTENCY_KEY_CLEANUP_INTERVAL", time.Hour)
+	viper.SetDefault("RATE_LIMITER", MemoryRateLimiter)
+	viper.SetDefault("AUTH_ENABLED", true)
+	viper.SetDefault("AUTH_ADMIN_API_KEY", "")
+	viper.SetDefault("AUTH_JWT_HMAC_SECRET", "")
+	viper.SetDefault("AUTH_JWT_PUBLIC_KEY_FILES", "")
+	viper.SetDefault("AUTH_JWT_ISSUER", "")
+	viper.SetDefault("AUTH_JWT_AUDIENCE", "")
+	viper.SetDefault("TRACING_EXPORTER", NoTracing)
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "http://localhost:4318")
+	viper.SetDefault("TRACING_SERVICE_NAME", "wallet")
+	viper.SetDefault("RATE_LIMIT_READ_RATE", 1000)
+	viper.SetDefault("RATE_LIMIT_READ_BURST", 5)
+	viper.SetDefault("RATE_LIMIT_WRITE_RATE", 1000)
+	viper.SetDefault("RATE_LIMIT_WRITE_BURST", 5)
+	viper.SetDefault("RATE_LIMIT_IDLE_TTL", 5*time.Minute)
+	viper.SetDefault("RATE_LIMIT_CLEANUP_INTERVAL", time.Minute)
+	viper.SetDefault("RATE_LIMIT_MAX_KEYS", 100000)
+	viper.SetDefault("WRITE_COALESCING_WINDOW", time.Duration(0))
+	viper.SetDefault("WRITE_COALESCING_MAX_BATCH", 100)
+	viper.SetDefault("BALANCE_CACHE_ENABLED", false)
+	viper.SetDefault("BALANCE_CACHE_TTL", 5*time.Second)
+	viper.SetDefault("WITHDRAWAL_LIMIT_PER_OPERATION", "")
+	viper.SetDefault("WITHDRAWAL_LIMIT_DAILY", "")
+	viper.SetDefault("WITHDRAWAL_LIMIT_MONTHLY", "")
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file, %s", err)
 	}
 
 	config := Config{}
-	if err := viper.Unmarshal(&config); err != nil {
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.TextUnmarshallerHookFunc(),
+	))
+	if err := viper.Unmarshal(&config, decodeHook); err != nil {
 		return nil, err
 	}
 
@@ -60,6 +213,45 @@ func loadConfig(file string) (*Config, error) {
 	return &config, nil
 }
 
+// BalanceCacheStaleness is how long a cached balance may be served, zero when caching is off.
+func (c Config) BalanceCacheStaleness() time.Duration {
+	if !c.BalanceCacheEnabled {
+		return 0
+	}
+	return c.BalanceCacheTTL
+}
+
+// ReplicaMaxLag is how long clients read from the primary after writing, zero without replicas.
+func (c Config) ReplicaMaxLag() time.Duration {
+	if len(c.DbReplicas) == 0 {
+		return 0
+	}
+	return c.DbReplicaMaxLag
+}
+
+// WithdrawalLimitDefaults groups the default withdrawal limits by currency.
+func (c Config) WithdrawalLimitDefaults() entities.CurrencyWithdrawalLimits {
+
+	defaults := entities.CurrencyWithdrawalLimits{}
+	for code, amount := range c.WithdrawalLimitPerOperation {
+		limits := defaults[code]
+		limits.PerOperation = amount
+		defaults[code] = limits
+	}
+	for code, amount := range c.WithdrawalLimitDaily {
+		limits := defaults[code]
+		limits.Daily = amount
+		defaults[code] = limits
+	}
+	for code, amount := range c.WithdrawalLimitMonthly {
+		limits := defaults[code]
+		limits.Monthly = amount
+		defaults[code] = limits
+	}
+
+	return defaults
+}
+
 func (c Config) validate() error {
 
 	var errs []error
@@ -68,6 +260,16 @@ func (c Config) validate() error {
 		errs = append(errs, fmt.Errorf("missing variable DbConnectionString"))
 	}
 
+	for _, replica := range c.DbReplicas {
+		if replica == "" {
+			errs = append(errs, fmt.Errorf("empty replica connection string"))
+		}
+	}
+
+	if c.DbReplicaMaxLag < 0 {
+		errs = append(errs, fmt.Errorf("invalid replica max lag: %s", c.DbReplicaMaxLag))
+	}
+
 	if c.Port <= 0 {
 		errs = append(errs, fmt.Errorf("invalid port: %d", c.Port))
 	}
@@ -76,6 +278,92 @@ func (c Config) validate() error {
 		errs = append(errs, fmt.Errorf("invalid mode: %s", c.Mode))
 	}
 
+	if c.LogFormat != JsonLogFormat && c.LogFormat != TextLogFormat {
+		errs = append(errs, fmt.Errorf("invalid log format: %s", c.LogFormat))
+	}
+
+	if c.ShutdownDelay < 0 {
+		errs = append(errs, fmt.Errorf("invalid shutdown delay: %s", c.ShutdownDelay))
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid shutdown timeout: %s", c.ShutdownTimeout))
+	}
+
+	if _, err := money.ParseRoundingMode(string(c.FxRoundingMode)); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.HoldDefaultTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid hold default ttl: %s", c.HoldDefaultTTL))
+	}
+
+	if c.HoldExpiryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid hold expiry interval: %s", c.HoldExpiryInterval))
+	}
+
+	if c.IdempotencyKeyTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid idempotency key ttl: %s", c.IdempotencyKeyTTL))
+	}
+
+	if c.IdempotencyKeyCleanupInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid idempotency key cleanup interval: %s", c.IdempotencyKeyCleanupInterval))
+	}
+
+	if c.RateLimiter != MemoryRateLimiter && c.RateLimiter != PostgresRateLimiter {
+		errs = append(errs, fmt.Errorf("invalid rate limiter: %s", c.RateLimiter))
+	}
+
+	if c.AdminApiKey != "" && len(c.AdminApiKey) < minAdminApiKeyLength {
+		errs = append(errs, fmt.Errorf("admin api key must be at least %d characters", minAdminApiKeyLength))
+	}
+
+	for _, file := range c.JwtPublicKeyFiles {
+		if file == "" {
+			errs = append(errs, fmt.Errorf("empty jwt public key file"))
+		}
+	}
+
+	if c.TracingExporter != NoTracing && c.TracingExporter != StdoutTracing && c.TracingExporter != OtlpTracing {
+		errs = append(errs, fmt.Errorf("invalid tracing exporter: %s", c.TracingExporter))
+	}
+
+	if c.TracingExporter == OtlpTracing && c.TracingEndpoint == "" {
+		errs = append(errs, fmt.Errorf("missing variable TracingEndpoint"))
+	}
+
+	if c.ReadRateLimit <= 0 || c.ReadRateBurst < 1 {
+		errs = append(errs, fmt.Errorf("invalid read rate limit: %v/s burst %d", c.ReadRateLimit, c.ReadRateBurst))
+	}
+
+	if c.WriteRateLimit <= 0 || c.WriteRateBurst < 1 {
+		errs = append(errs, fmt.Errorf("invalid write rate limit: %v/s burst %d", c.WriteRateLimit, c.WriteRateBurst))
+	}
+
+	if c.RateLimitIdleTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid rate limit idle ttl: %s", c.RateLimitIdleTTL))
+	}
+
+	if c.RateLimitCleanupInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid rate limit cleanup interval: %s", c.RateLimitCleanupInterval))
+	}
+
+	if c.RateLimitMaxKeys < 1 {
+		errs = append(errs, fmt.Errorf("invalid rate limit max keys: %d", c.RateLimitMaxKeys))
+	}
+
+	if c.WriteCoalescingWindow < 0 {
+		errs = append(errs, fmt.Errorf("invalid write coalescing window: %s", c.WriteCoalescingWindow))
+	}
+
+	if c.WriteCoalescingMaxBatch < 1 {
+		errs = append(errs, fmt.Errorf("invalid write coalescing max batch: %d", c.WriteCoalescingMaxBatch))
+	}
+
+	if c.BalanceCacheEnabled && c.BalanceCacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid balance cache ttl: %s", c.BalanceCacheTTL))
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("errors occurred: %w", errors.Join(errs...))
 	}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"strconv"
@@ -30,3 +31,29 @@ func TestConfig_EnvironmentOverride(t *testing.T) {
 	assert.Equal(override.Port, cfg.Port)
 	assert.Equal(override.Mode, cfg.Mode)
 }
+
+func TestConfig_WithdrawalLimitDefaultsPerCurrency(t *testing.T) {
+
+	assert := assert.New(t)
+
+	t.Setenv("WITHDRAWAL_LIMIT_PER_OPERATION", "USD:1000, JPY:150000")
+	t.Setenv("WITHDRAWAL_LIMIT_DAILY", "USD:5000")
+
+	cfg, err := loadConfig("../../configs/config.env")
+	assert.NoError(err)
+
+	defaults := cfg.WithdrawalLimitDefaults()
+	assert.True(decimal.RequireFromString("1000").Equal(defaults["USD"].PerOperation))
+	assert.True(decimal.RequireFromString("5000").Equal(defaults["USD"].Daily))
+	assert.True(decimal.RequireFromString("150000").Equal(defaults["JPY"].PerOperation))
+	assert.True(defaults["JPY"].Daily.IsZero())
+	assert.NotContains(defaults, "EUR")
+}
+
+func TestCurrencyAmounts_WhenInvalid_ShouldFail(t *testing.T) {
+
+	for _, text := range []string{"1000", "XXX:10", "USD:abc", "USD:-1", "JPY:0.5"} {
+		var amounts CurrencyAmounts
+		assert.Error(t, amounts.UnmarshalText([]byte(text)), text)
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"test-task/internal/money"
+)
+
+// Amount accepts either a decimal string in major units ("10.50")
+// or an integer number of minor units (1050).
+type Amount struct {
+	value      decimal.Decimal
+	minorUnits bool
+}
+
+func AmountFromString(value string) Amount {
+	return Amount{value: decimal.RequireFromString(value)}
+}
+
+func AmountFromMinorUnits(units int64) Amount {
+	return Amount{value: decimal.NewFromInt(units), minorUnits: true}
+}
+
+func (a Amount) Decimal(scale int32) decimal.Decimal {
+	if a.minorUnits {
+		return money.FromMinorUnits(a.value.IntPart(), scale)
+	}
+	return a.value
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		value, err := decimal.NewFromString(s)
+		if err != nil {
+			return fmt.Errorf("amount is not a valid decimal: %s", s)
+		}
+		*a = Amount{value: value}
+		return nil
+	}
+
+	var units int64
+	if err := json.Unmarshal(data, &units); err != nil {
+		return fmt.Errorf("amount must be a decimal string or an integer number of minor units")
+	}
+	*a = AmountFromMinorUnits(units)
+	return nil
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.minorUnits {
+		return []byte(a.value.String()), nil
+	}
+	return json.Marshal(a.value.String())
+}
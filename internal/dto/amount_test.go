@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAmount_UnmarshalDecimalString(t *testing.T) {
+
+	var amount Amount
+	err := json.Unmarshal([]byte(`"10.05"`), &amount)
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("10.05").Equal(amount.Decimal(2)))
+}
+
+func TestAmount_UnmarshalMinorUnits(t *testing.T) {
+
+	var amount Amount
+	err := json.Unmarshal([]byte(`1005`), &amount)
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("10.05").Equal(amount.Decimal(2)))
+	assert.True(t, decimal.NewFromInt(1005).Equal(amount.Decimal(0)))
+}
+
+func TestAmount_UnmarshalFloat_ShouldFail(t *testing.T) {
+
+	var amount Amount
+	err := json.Unmarshal([]byte(`10.05`), &amount)
+
+	assert.Error(t, err)
+}
+
+func TestAmount_MarshalRoundTrip(t *testing.T) {
+
+	for _, original := range []Amount{AmountFromString("0.01"), AmountFromMinorUnits(1)} {
+		data, err := json.Marshal(original)
+		assert.NoError(t, err)
+
+		var decoded Amount
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, original.Decimal(2).Equal(decoded.Decimal(2)))
+	}
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type ApiKeyRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	Scopes  []string `json:"scopes" binding:"required"`
+	OwnerID *string  `json:"ownerId"`
+	Admin   bool     `json:"admin"`
+}
+
+type ApiKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	OwnerID   *string    `json:"ownerId,omitempty"`
+	Admin     bool       `json:"admin"`
+	Key       string     `json:"key,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
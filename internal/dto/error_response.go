@@ -1,5 +1,14 @@
 package dto
 
+import "time"
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+type LimitExceededResponse struct {
+	Error   string     `json:"error"`
+	Code    string     `json:"code"`
+	Limit   string     `json:"limit"`
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type FxRateRequest struct {
+	BaseCurrency  string `json:"baseCurrency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Rate          string `json:"rate" binding:"required"`
+}
+
+type FxRate struct {
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Rate          string    `json:"rate"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
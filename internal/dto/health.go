@@ -0,0 +1,26 @@
+package dto
+
+type Health struct {
+	Status string `json:"status"`
+}
+
+type Readiness struct {
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Migrations *MigrationState `json:"migrations,omitempty"`
+	Pool       *PoolStats      `json:"pool,omitempty"`
+}
+
+type MigrationState struct {
+	Version         uint `json:"version"`
+	ExpectedVersion uint `json:"expectedVersion"`
+	Dirty           bool `json:"dirty"`
+}
+
+type PoolStats struct {
+	MaxOpen    int     `json:"maxOpen"`
+	Open       int     `json:"open"`
+	InUse      int     `json:"inUse"`
+	WaitCount  int64   `json:"waitCount"`
+	Saturation float64 `json:"saturation"`
+}
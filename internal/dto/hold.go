@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type HoldRequest struct {
+	WalletID   string `json:"walletId"`
+	Amount     Amount `json:"amount"`
+	Currency   string `json:"currency"`
+	TtlSeconds int64  `json:"ttlSeconds"`
+}
+
+type CaptureRequest struct {
+	Amount   *Amount `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+type Hold struct {
+	ID             string    `json:"id"`
+	WalletID       string    `json:"walletId"`
+	Amount         string    `json:"amount"`
+	Currency       string    `json:"currency"`
+	CapturedAmount *string   `json:"capturedAmount,omitempty"`
+	Status         string    `json:"status"`
+	TransactionID  *string   `json:"transactionId,omitempty"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
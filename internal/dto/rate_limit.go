@@ -0,0 +1,17 @@
+package dto
+
+type RateLimitPolicy struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type WalletRateLimitsRequest struct {
+	Read  *RateLimitPolicy `json:"read"`
+	Write *RateLimitPolicy `json:"write"`
+}
+
+type WalletRateLimits struct {
+	WalletID string           `json:"walletId"`
+	Read     *RateLimitPolicy `json:"read,omitempty"`
+	Write    *RateLimitPolicy `json:"write,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type Transaction struct {
+	ID            string    `json:"id"`
+	WalletID      string    `json:"walletId"`
+	OperationType string    `json:"operationType"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	BalanceAfter  string    `json:"balanceAfter"`
+	CreatedAt     time.Time `json:"createdAt"`
+	TransferID    *string   `json:"transferId,omitempty"`
+	FxRate        *string   `json:"fxRate,omitempty"`
+}
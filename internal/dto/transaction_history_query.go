@@ -0,0 +1,11 @@
+package dto
+
+type TransactionHistoryQuery struct {
+	Limit         int    `form:"limit"`
+	Cursor        string `form:"cursor"`
+	OperationType string `form:"operationType"`
+	MinAmount     string `form:"minAmount"`
+	MaxAmount     string `form:"maxAmount"`
+	From          string `form:"from"`
+	To            string `form:"to"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type TransferRequest struct {
+	FromWalletID string `json:"fromWalletId"`
+	ToWalletID   string `json:"toWalletId"`
+	Amount       Amount `json:"amount"`
+	Currency     string `json:"currency"`
+}
+
+type Transfer struct {
+	ID           string    `json:"id"`
+	FromWalletID string    `json:"fromWalletId"`
+	ToWalletID   string    `json:"toWalletId"`
+	Amount       string    `json:"amount"`
+	Currency     string    `json:"currency"`
+	ToAmount     string    `json:"toAmount"`
+	ToCurrency   string    `json:"toCurrency"`
+	FxRate       *string   `json:"fxRate,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+type CreateWalletRequest struct {
+	InitialBalance *Amount `json:"initialBalance"`
+	OwnerRef       *string `json:"ownerRef"`
+	Currency       string  `json:"currency"`
+}
+
+type Wallet struct {
+	ID          string    `json:"id"`
+	Balance     string    `json:"balance"`
+	Available   string    `json:"available"`
+	CreditLimit string    `json:"creditLimit"`
+	Currency    string    `json:"currency"`
+	OwnerRef    *string   `json:"ownerRef,omitempty"`
+	OwnerID     *string   `json:"ownerId,omitempty"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	WithdrawalLimits WithdrawalLimits `json:"withdrawalLimits"`
+}
+
+type CreditLimitRequest struct {
+	CreditLimit Amount `json:"creditLimit"`
+	Currency    string `json:"currency"`
+}
+
+type WithdrawalLimits struct {
+	PerOperation *string `json:"perOperation,omitempty"`
+	Daily        *string `json:"daily,omitempty"`
+	Monthly      *string `json:"monthly,omitempty"`
+}
+
+type WithdrawalLimitsRequest struct {
+	PerOperation *Amount `json:"perOperation"`
+	Daily        *Amount `json:"daily"`
+	Monthly      *Amount `json:"monthly"`
+	Currency     string  `json:"currency"`
+}
+
+type WalletStatusChange struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type WalletPage struct {
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+type WalletListQuery struct {
+	Limit      int    `form:"limit"`
+	Cursor     string `form:"cursor"`
+	OwnerRef   string `form:"ownerRef"`
+	Status     string `form:"status"`
+	Currency   string `form:"currency"`
+	MinBalance string `form:"minBalance"`
+	MaxBalance string `form:"maxBalance"`
+}
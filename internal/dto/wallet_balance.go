@@ -1,5 +1,7 @@
 package dto
 
 type WalletBalance struct {
-	Balance float64 `json:"balance"`
+	Balance   string `json:"balance"`
+	Available string `json:"available"`
+	Currency  string `json:"currency"`
 }
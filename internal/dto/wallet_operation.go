@@ -1,7 +1,8 @@
 package dto
 
 type WalletOperation struct {
-	WalledID      string  `json:"walletId"`
-	OperationType string  `json:"operationType"`
-	Amount        float64 `json:"amount"`
+	WalledID      string `json:"walletId"`
+	OperationType string `json:"operationType"`
+	Amount        Amount `json:"amount"`
+	Currency      string `json:"currency"`
 }
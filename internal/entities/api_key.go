@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"github.com/lib/pq"
+	"slices"
+	"time"
+)
+
+const (
+	ScopeRead     = "read"
+	ScopeDeposit  = "deposit"
+	ScopeWithdraw = "withdraw"
+)
+
+var Scopes = []string{ScopeRead, ScopeDeposit, ScopeWithdraw}
+
+// ApiKey is only ever stored as a hash, the key itself is shown once when issued.
+// A key acts on the wallets of its owner, admin keys on any wallet and on the
+// admin API.
+type ApiKey struct {
+	ID        string         `db:"id"`
+	Name      string         `db:"name"`
+	KeyHash   string         `db:"key_hash"`
+	Scopes    pq.StringArray `db:"scopes"`
+	OwnerID   *string        `db:"owner_id"`
+	Admin     bool           `db:"admin"`
+	CreatedAt time.Time      `db:"created_at"`
+	RevokedAt *time.Time     `db:"revoked_at"`
+}
+
+func (k ApiKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "github.com/shopspring/decimal"
+
+// BalanceChange is a single ledger operation on a wallet, debits are
+// checked against Limits where the wallet sets none of its own.
+type BalanceChange struct {
+	WalletID       string
+	Currency       string
+	OperationType  string
+	Delta          decimal.Decimal
+	Limits         CurrencyWithdrawalLimits
+	IdempotencyKey *IdempotencyKey
+	// RequestID tags the logs of a change applied outside its request's context.
+	RequestID string
+}
+
+type BalanceChangeResult struct {
+	Transaction Transaction
+	Err         error
+}
@@ -0,0 +1,25 @@
+package entities
+
+type DbHealth struct {
+	Version         uint
+	Dirty           bool
+	ExpectedVersion uint
+
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	WaitCount          int64
+}
+
+// Migrated reports whether the schema is exactly at the version this build expects.
+func (h DbHealth) Migrated() bool {
+	return !h.Dirty && h.Version == h.ExpectedVersion
+}
+
+// Saturation is the share of the pool in use, 1 when every connection is busy.
+func (h DbHealth) Saturation() float64 {
+	if h.MaxOpenConnections <= 0 {
+		return 0
+	}
+	return float64(h.InUse) / float64(h.MaxOpenConnections)
+}
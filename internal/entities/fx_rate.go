@@ -0,0 +1,13 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type FxRate struct {
+	BaseCurrency  string          `db:"base_currency"`
+	QuoteCurrency string          `db:"quote_currency"`
+	Rate          decimal.Decimal `db:"rate"`
+	UpdatedAt     time.Time       `db:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+type Hold struct {
+	ID             string           `db:"id"`
+	WalletID       string           `db:"wallet_id"`
+	Currency       string           `db:"currency"`
+	Amount         decimal.Decimal  `db:"amount"`
+	CapturedAmount *decimal.Decimal `db:"captured_amount"`
+	Status         HoldStatus       `db:"status"`
+	TransactionID  *string          `db:"transaction_id"`
+	ExpiresAt      time.Time        `db:"expires_at"`
+	CreatedAt      time.Time        `db:"created_at"`
+	UpdatedAt      time.Time        `db:"updated_at"`
+}
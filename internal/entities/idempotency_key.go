@@ -0,0 +1,6 @@
+package entities
+
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// Cursor marks where the next page starts: wallets page by CreatedAt and ID,
+// ledger entries by Seq.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+	Seq       int64
+}
+
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+}
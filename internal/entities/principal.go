@@ -0,0 +1,20 @@
+package entities
+
+// Principal is the authenticated caller, either a JWT subject or an API key
+// acting for its owner.
+type Principal struct {
+	Subject string
+	Admin   bool
+	ApiKey  *ApiKey
+}
+
+// HasScope limits API keys to their scopes, token holders are limited by ownership instead.
+func (p Principal) HasScope(scope string) bool {
+	return p.ApiKey == nil || p.ApiKey.HasScope(scope)
+}
+
+// Owns reports whether the principal may act on wallet: admins on any, others
+// on the wallets owned by their subject.
+func (p Principal) Owns(wallet Wallet) bool {
+	return p.Admin || (p.Subject != "" && wallet.OwnerID != nil && *wallet.OwnerID == p.Subject)
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// RateLimitPolicy allows Burst requests at once, refilled at Rate per second.
+type RateLimitPolicy struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitDecision tells, for a denied request, how long until a token is available.
+type RateLimitDecision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// WalletRateLimits overrides the default policies for one wallet,
+// a nil rate and burst fall back to the default.
+type WalletRateLimits struct {
+	WalletID   string   `db:"wallet_id"`
+	ReadRate   *float64 `db:"read_rate"`
+	ReadBurst  *int     `db:"read_burst"`
+	WriteRate  *float64 `db:"write_rate"`
+	WriteBurst *int     `db:"write_burst"`
+}
+
+func (l WalletRateLimits) Read(defaults RateLimitPolicy) RateLimitPolicy {
+	if l.ReadRate == nil || l.ReadBurst == nil {
+		return defaults
+	}
+	return RateLimitPolicy{Rate: *l.ReadRate, Burst: *l.ReadBurst}
+}
+
+func (l WalletRateLimits) Write(defaults RateLimitPolicy) RateLimitPolicy {
+	if l.WriteRate == nil || l.WriteBurst == nil {
+		return defaults
+	}
+	return RateLimitPolicy{Rate: *l.WriteRate, Burst: *l.WriteBurst}
+}
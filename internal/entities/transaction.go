@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	Deposit     = "DEPOSIT"
+	Withdraw    = "WITHDRAW"
+	TransferOut = "TRANSFER_OUT"
+	TransferIn  = "TRANSFER_IN"
+	Capture     = "CAPTURE"
+)
+
+// Debits lists the operation types that take money out of a wallet.
+var Debits = []string{Withdraw, TransferOut, Capture}
+
+// Transaction is a ledger entry. Seq is assigned once the wallet row is
+// locked, so it orders a wallet's entries the way they were applied, which
+// created_at, the time their database transaction started, does not.
+type Transaction struct {
+	ID            string           `db:"id"`
+	Seq           int64            `db:"seq"`
+	WalletID      string           `db:"wallet_id"`
+	OperationType string           `db:"operation_type"`
+	Amount        decimal.Decimal  `db:"amount"`
+	BalanceAfter  decimal.Decimal  `db:"balance_after"`
+	CreatedAt     time.Time        `db:"created_at"`
+	TransferID    *string          `db:"transfer_id"`
+	Currency      string           `db:"currency"`
+	FxRate        *decimal.Decimal `db:"fx_rate"`
+}
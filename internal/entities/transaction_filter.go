@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type TransactionFilter struct {
+	WalletID      string
+	OperationType string
+	MinAmount     *decimal.Decimal
+	MaxAmount     *decimal.Decimal
+	From          *time.Time
+	To            *time.Time
+	After         *Cursor
+	Limit         int
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type Transfer struct {
+	ID           string           `db:"id"`
+	FromWalletID string           `db:"from_wallet_id"`
+	ToWalletID   string           `db:"to_wallet_id"`
+	Amount       decimal.Decimal  `db:"amount"`
+	CreatedAt    time.Time        `db:"created_at"`
+	Currency     string           `db:"currency"`
+	ToAmount     decimal.Decimal  `db:"to_amount"`
+	ToCurrency   string           `db:"to_currency"`
+	FxRate       *decimal.Decimal `db:"fx_rate"`
+}
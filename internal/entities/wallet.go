@@ -1,6 +1,60 @@
 package entities
 
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type WalletStatus string
+
+const (
+	WalletActive WalletStatus = "active"
+	WalletFrozen WalletStatus = "frozen"
+	WalletClosed WalletStatus = "closed"
+)
+
+var WalletStatuses = []WalletStatus{WalletActive, WalletFrozen, WalletClosed}
+
+// Allows reports whether a balance change of delta is permitted:
+// frozen wallets only accept credits, closed wallets accept nothing.
+func (s WalletStatus) Allows(delta decimal.Decimal) bool {
+	switch s {
+	case WalletActive:
+		return true
+	case WalletFrozen:
+		return delta.IsPositive()
+	default:
+		return false
+	}
+}
+
 type Wallet struct {
-	ID      string
-	Balance float64
+	ID          string          `db:"id"`
+	Balance     decimal.Decimal `db:"balance"`
+	OwnerRef    *string         `db:"owner_ref"`
+	OwnerID     *string         `db:"owner_id"`
+	CreatedAt   time.Time       `db:"created_at"`
+	Status      WalletStatus    `db:"status"`
+	Currency    string          `db:"currency"`
+	Held        decimal.Decimal `db:"held"`
+	CreditLimit decimal.Decimal `db:"credit_limit"`
+
+	PerOperationWithdrawalLimit *decimal.Decimal `db:"per_operation_withdrawal_limit"`
+	DailyWithdrawalLimit        *decimal.Decimal `db:"daily_withdrawal_limit"`
+	MonthlyWithdrawalLimit      *decimal.Decimal `db:"monthly_withdrawal_limit"`
+}
+
+// Available is what the wallet can still spend: the balance not reserved
+// by active holds plus the unused credit limit.
+func (w Wallet) Available() decimal.Decimal {
+	return w.Balance.Sub(w.Held).Add(w.CreditLimit)
+}
+
+// WithdrawalLimits applies the wallet's own limits over the defaults for its currency.
+func (w Wallet) WithdrawalLimits(defaults CurrencyWithdrawalLimits) WithdrawalLimits {
+	return defaults[w.Currency].Override(WithdrawalLimitOverrides{
+		PerOperation: w.PerOperationWithdrawalLimit,
+		Daily:        w.DailyWithdrawalLimit,
+		Monthly:      w.MonthlyWithdrawalLimit,
+	})
 }
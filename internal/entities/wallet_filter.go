@@ -0,0 +1,14 @@
+package entities
+
+import "github.com/shopspring/decimal"
+
+type WalletFilter struct {
+	OwnerRef   string
+	OwnerID    string
+	Status     WalletStatus
+	Currency   string
+	MinBalance *decimal.Decimal
+	MaxBalance *decimal.Decimal
+	After      *Cursor
+	Limit      int
+}
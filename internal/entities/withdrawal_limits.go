@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	PerOperationLimit = "per_operation"
+	DailyLimit        = "daily"
+	MonthlyLimit      = "monthly"
+)
+
+const (
+	DailyLimitWindow   = 24 * time.Hour
+	MonthlyLimitWindow = 30 * 24 * time.Hour
+)
+
+// WithdrawalLimits caps a single debit and the debits made over rolling
+// daily and monthly windows, a zero amount means no limit.
+type WithdrawalLimits struct {
+	PerOperation decimal.Decimal
+	Daily        decimal.Decimal
+	Monthly      decimal.Decimal
+}
+
+// CurrencyWithdrawalLimits are the default limits by currency code, a
+// currency without an entry has no default limits.
+type CurrencyWithdrawalLimits map[string]WithdrawalLimits
+
+// WithdrawalLimitOverrides are the limits set on a wallet, nil falls back to the default.
+type WithdrawalLimitOverrides struct {
+	PerOperation *decimal.Decimal
+	Daily        *decimal.Decimal
+	Monthly      *decimal.Decimal
+}
+
+func (l WithdrawalLimits) Override(overrides WithdrawalLimitOverrides) WithdrawalLimits {
+	if overrides.PerOperation != nil {
+		l.PerOperation = *overrides.PerOperation
+	}
+	if overrides.Daily != nil {
+		l.Daily = *overrides.Daily
+	}
+	if overrides.Monthly != nil {
+		l.Monthly = *overrides.Monthly
+	}
+	return l
+}
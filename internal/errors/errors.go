@@ -1,10 +1,57 @@
 package errors
 
 import (
+	"fmt"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"time"
 )
 
 var UnsupportedOperation = errors.New("operation unsupported")
 var InsufficientBalance = errors.New("insufficient balance")
 var TooManyRequests = errors.New("too many requests")
 var NotFound = errors.New("not found")
+var IdempotencyConflict = errors.New("idempotency key already used with a different request")
+var WalletUnavailable = errors.New("wallet unavailable")
+var AmountTooSmall = errors.New("amount too small")
+var CurrencyMismatch = errors.New("currency mismatch")
+var HoldNotActive = errors.New("hold is not active")
+var InvalidStatusTransition = errors.New("invalid wallet status transition")
+var Unauthorized = errors.New("unauthorized")
+var Forbidden = errors.New("forbidden")
+
+// RateLimited is a TooManyRequests carrying the policy that was hit.
+type RateLimited struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimited) Error() string {
+	return fmt.Sprintf("%s, retry after %s", TooManyRequests, e.RetryAfter)
+}
+
+func (e *RateLimited) Unwrap() error {
+	return TooManyRequests
+}
+
+var WithdrawalLimitExceeded = errors.New("withdrawal limit exceeded")
+
+// LimitExceeded names the withdrawal limit an operation hit and, for rolling
+// windows, when enough spending leaves the window for it to go through.
+type LimitExceeded struct {
+	Limit   string
+	Amount  decimal.Decimal
+	ResetAt *time.Time
+}
+
+func (e *LimitExceeded) Error() string {
+	message := fmt.Sprintf("%s: %s limit of %s", WithdrawalLimitExceeded, e.Limit, e.Amount)
+	if e.ResetAt != nil {
+		message += fmt.Sprintf(", resets at %s", e.ResetAt.UTC().Format(time.RFC3339))
+	}
+	return message
+}
+
+func (e *LimitExceeded) Unwrap() error {
+	return WithdrawalLimitExceeded
+}
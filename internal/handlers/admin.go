@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"test-task/internal/dto"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+	"test-task/internal/services"
+)
+
+type adminWalletService interface {
+	ChangeStatus(ctx context.Context, id string, status entities.WalletStatus) (entities.Wallet, error)
+	SetCreditLimit(ctx context.Context, id string, creditLimit services.WalletCreditLimit) (entities.Wallet, error)
+	SetWithdrawalLimits(ctx context.Context, id string, limits services.WalletWithdrawalLimits) (entities.Wallet, error)
+	SetRateLimits(ctx context.Context, limits services.WalletRateLimits) (entities.WalletRateLimits, error)
+}
+
+type fxRateService interface {
+	Upsert(ctx context.Context, rate entities.FxRate) (entities.FxRate, error)
+	List(ctx context.Context) ([]entities.FxRate, error)
+}
+
+type apiKeyService interface {
+	Issue(ctx context.Context, issue services.ApiKeyIssue) (entities.ApiKey, string, error)
+	Revoke(ctx context.Context, id string) (entities.ApiKey, error)
+}
+
+type AdminHandler struct {
+	wallets adminWalletService
+	fxRates fxRateService
+	apiKeys apiKeyService
+}
+
+func NewAdminHandler(wallets adminWalletService, fxRates fxRateService, apiKeys apiKeyService) *AdminHandler {
+	return &AdminHandler{wallets: wallets, fxRates: fxRates, apiKeys: apiKeys}
+}
+
+func (h *AdminHandler) ChangeWalletStatus(ctx *gin.Context) {
+
+	walletID := ctx.Param("id")
+
+	if _, err := uuid.Parse(walletID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "wallet ID must be uuid"})
+		return
+	}
+
+	var request dto.WalletStatusChange
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	status, err := services.ParseWalletStatus(request.Status)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wallet, err := h.wallets.ChangeStatus(ctx, walletID, status)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toWalletDto(wallet))
+}
+
+func (h *AdminHandler) SetCreditLimit(ctx *gin.Context) {
+
+	walletID := ctx.Param("id")
+
+	if _, err := uuid.Parse(walletID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "wallet ID must be uuid"})
+		return
+	}
+
+	var request dto.CreditLimitRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	currency, err := money.ParseCurrency(request.Currency)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	creditLimit, err := services.NewWalletCreditLimit(currency, request.CreditLimit.Decimal(currency.Scale))
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wallet, err := h.wallets.SetCreditLimit(ctx, walletID, *creditLimit)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toWalletDto(wallet))
+}
+
+func (h *AdminHandler) SetWithdrawalLimits(ctx *gin.Context) {
+
+	walletID := ctx.Param("id")
+
+	if _, err := uuid.Parse(walletID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "wallet ID must be uuid"})
+		return
+	}
+
+	var request dto.WithdrawalLimitsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	currency, err := money.ParseCurrency(request.Currency)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	limits, err := services.NewWalletWithdrawalLimits(currency, entities.WithdrawalLimitOverrides{
+		PerOperation: limitAmount(request.PerOperation, currency),
+		Daily:        limitAmount(request.Daily, currency),
+		Monthly:      limitAmount(request.Monthly, currency),
+	})
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wallet, err := h.wallets.SetWithdrawalLimits(ctx, walletID, *limits)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toWalletDto(wallet))
+}
+
+func limitAmount(amount *dto.Amount, currency money.Currency) *decimal.Decimal {
+	if amount == nil {
+		return nil
+	}
+	value := amount.Decimal(currency.Scale)
+	return &value
+}
+
+func (h *AdminHandler) SetRateLimits(ctx *gin.Context) {
+
+	var request dto.WalletRateLimitsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	limits, err := parseWalletRateLimits(ctx.Param("id"), request)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	stored, err := h.wallets.SetRateLimits(ctx, *limits)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toWalletRateLimitsDto(stored))
+}
+
+func parseWalletRateLimits(walletID string, request dto.WalletRateLimitsRequest) (*services.WalletRateLimits, error) {
+
+	var read, write *entities.RateLimitPolicy
+	var err error
+
+	if request.Read != nil {
+		if read, err = services.NewRateLimitPolicy(request.Read.Rate, request.Read.Burst); err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+	}
+
+	if request.Write != nil {
+		if write, err = services.NewRateLimitPolicy(request.Write.Rate, request.Write.Burst); err != nil {
+			return nil, fmt.Errorf("write: %w", err)
+		}
+	}
+
+	return services.NewWalletRateLimits(walletID, read, write)
+}
+
+func toWalletRateLimitsDto(limits entities.WalletRateLimits) dto.WalletRateLimits {
+	response := dto.WalletRateLimits{WalletID: limits.WalletID}
+	if limits.ReadRate != nil && limits.ReadBurst != nil {
+		response.Read = &dto.RateLimitPolicy{Rate: *limits.ReadRate, Burst: *limits.ReadBurst}
+	}
+	if limits.WriteRate != nil && limits.WriteBurst != nil {
+		response.Write = &dto.RateLimitPolicy{Rate: *limits.WriteRate, Burst: *limits.WriteBurst}
+	}
+	return response
+}
+
+func (h *AdminHandler) UpsertFxRate(ctx *gin.Context) {
+
+	var request dto.FxRateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rate, err := parseFxRate(request)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	stored, err := h.fxRates.Upsert(ctx, *rate)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toFxRateDto(stored))
+}
+
+func (h *AdminHandler) ListFxRates(ctx *gin.Context) {
+
+	rates, err := h.fxRates.List(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	response := make([]dto.FxRate, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, toFxRateDto(rate))
+	}
+
+	ctx.JSON(200, response)
+}
+
+func parseFxRate(request dto.FxRateRequest) (*entities.FxRate, error) {
+
+	base, err := money.ParseCurrency(request.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := money.ParseCurrency(request.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := decimal.NewFromString(request.Rate)
+	if err != nil {
+		return nil, fmt.Errorf("rate is not a valid decimal")
+	}
+
+	return services.NewFxRate(base, quote, rate)
+}
+
+func toFxRateDto(rate entities.FxRate) dto.FxRate {
+	return dto.FxRate{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate.String(),
+		UpdatedAt:     rate.UpdatedAt,
+	}
+}
+
+func (h *AdminHandler) IssueApiKey(ctx *gin.Context) {
+
+	var request dto.ApiKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	issue, err := services.NewApiKeyIssue(request.Name, request.Scopes, request.OwnerID, request.Admin)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	apiKey, key, err := h.apiKeys.Issue(ctx, *issue)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	response := toApiKeyDto(apiKey)
+	response.Key = key
+	ctx.JSON(201, response)
+}
+
+func (h *AdminHandler) RevokeApiKey(ctx *gin.Context) {
+
+	keyID := ctx.Param("id")
+	if _, err := uuid.Parse(keyID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "api key ID must be uuid"})
+		return
+	}
+
+	apiKey, err := h.apiKeys.Revoke(ctx, keyID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toApiKeyDto(apiKey))
+}
+
+func toApiKeyDto(apiKey entities.ApiKey) dto.ApiKey {
+	return dto.ApiKey{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Scopes:    apiKey.Scopes,
+		OwnerID:   apiKey.OwnerID,
+		Admin:     apiKey.Admin,
+		CreatedAt: apiKey.CreatedAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+	"test-task/internal/auth"
+	"test-task/internal/dto"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const (
+	apiKeyHeader        = "X-API-Key"
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	// maxOperationBodyBytes bounds what RequireOperationScope buffers, an operation is well under it
+	maxOperationBodyBytes = 64 << 10
+)
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (entities.ApiKey, error)
+}
+
+type tokenVerifier interface {
+	Verify(token string) (entities.Principal, error)
+}
+
+type AuthHandler struct {
+	keys   apiKeyAuthenticator
+	tokens tokenVerifier
+}
+
+func NewAuthHandler(keys apiKeyAuthenticator, tokens tokenVerifier) *AuthHandler {
+	return &AuthHandler{keys: keys, tokens: tokens}
+}
+
+// Authenticate rejects requests without a valid bearer token or X-API-Key
+// and puts the caller in the request context for the checks that follow.
+func (h *AuthHandler) Authenticate(ctx *gin.Context) {
+
+	principal, err := h.principal(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), principal))
+}
+
+func (h *AuthHandler) principal(ctx *gin.Context) (entities.Principal, error) {
+
+	if token, ok := strings.CutPrefix(ctx.GetHeader(authorizationHeader), bearerPrefix); ok {
+		return h.tokens.Verify(token)
+	}
+
+	if key := ctx.GetHeader(apiKeyHeader); key != "" {
+		apiKey, err := h.keys.Authenticate(ctx, key)
+		if err != nil {
+			return entities.Principal{}, err
+		}
+		principal := entities.Principal{Admin: apiKey.Admin, ApiKey: &apiKey}
+		if apiKey.OwnerID != nil {
+			principal.Subject = *apiKey.OwnerID
+		}
+		return principal, nil
+	}
+
+	return entities.Principal{}, fmt.Errorf("%w: bearer token or api key is required", errs.Unauthorized)
+}
+
+// RequireAdmin lets through admin token holders and admin API keys only.
+func (h *AuthHandler) RequireAdmin(ctx *gin.Context) {
+	if principal, ok := auth.PrincipalFrom(ctx); !ok || !principal.Admin {
+		_ = ctx.Error(fmt.Errorf("%w: admin access is required", errs.Forbidden))
+		ctx.Abort()
+	}
+}
+
+func (h *AuthHandler) RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requireScope(ctx, scope)
+	}
+}
+
+// RequireOperationScope checks the scope of the operation named in the body,
+// deposit or withdraw, leaving the body in place for the handler. Unknown
+// operations are passed on for the handler to reject.
+func (h *AuthHandler) RequireOperationScope(ctx *gin.Context) {
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxOperationBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.AbortWithStatusJSON(413, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var operation dto.WalletOperation
+	if err := json.Unmarshal(body, &operation); err != nil {
+		ctx.AbortWithStatusJSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	switch operation.OperationType {
+	case entities.Deposit:
+		requireScope(ctx, entities.ScopeDeposit)
+	case entities.Withdraw:
+		requireScope(ctx, entities.ScopeWithdraw)
+	}
+}
+
+func requireScope(ctx *gin.Context, scope string) {
+	if principal, ok := auth.PrincipalFrom(ctx); !ok || !principal.HasScope(scope) {
+		_ = ctx.Error(fmt.Errorf("%w: api key lacks scope %s", errs.Forbidden, scope))
+		ctx.Abort()
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"test-task/internal/entities"
+	"time"
+)
+
+func encodeCursor(cursor *entities.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	raw := cursor.CreatedAt.Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (*entities.Cursor, error) {
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &entities.Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+func encodeLedgerCursor(cursor *entities.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(cursor.Seq, 10)))
+}
+
+func decodeLedgerCursor(value string) (*entities.Cursor, error) {
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	seq, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || seq <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &entities.Cursor{Seq: seq}, nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"sync/atomic"
+	"test-task/internal/dto"
+	"test-task/internal/entities"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type dbHealthChecker interface {
+	Health(ctx context.Context) (entities.DbHealth, error)
+}
+
+type HealthHandler struct {
+	db    dbHealthChecker
+	ready atomic.Bool
+}
+
+func NewHealthHandler(db dbHealthChecker) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// SetReady switches readiness, it is turned off first on shutdown so load
+// balancers stop routing before the server stops accepting connections.
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+func (h *HealthHandler) Live(ctx *gin.Context) {
+	ctx.JSON(200, dto.Health{Status: "ok"})
+}
+
+func (h *HealthHandler) Ready(ctx *gin.Context) {
+
+	if !h.ready.Load() {
+		ctx.JSON(503, dto.Readiness{Status: "unavailable", Error: "shutting down"})
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	health, err := h.db.Health(checkCtx)
+	if err != nil {
+		ctx.JSON(503, dto.Readiness{Status: "unavailable", Error: "database: " + err.Error()})
+		return
+	}
+
+	response := dto.Readiness{
+		Status: "ready",
+		Migrations: &dto.MigrationState{
+			Version:         health.Version,
+			ExpectedVersion: health.ExpectedVersion,
+			Dirty:           health.Dirty,
+		},
+		Pool: &dto.PoolStats{
+			MaxOpen:    health.MaxOpenConnections,
+			Open:       health.OpenConnections,
+			InUse:      health.InUse,
+			WaitCount:  health.WaitCount,
+			Saturation: health.Saturation(),
+		},
+	}
+
+	if !health.Migrated() {
+		response.Status = "unavailable"
+		response.Error = "database schema is not at the expected migration version"
+		ctx.JSON(503, response)
+		return
+	}
+
+	ctx.JSON(200, response)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"test-task/internal/dto"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+	"test-task/internal/services"
+	"test-task/internal/tracing"
+	"time"
+)
+
+func (h *WalletHandler) CreateHold(ctx *gin.Context) {
+
+	var request dto.HoldRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	annotateWallet(ctx, request.WalletID)
+
+	currency, err := money.ParseCurrency(request.Currency)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	hold, err := services.NewWalletHold(request.WalletID, currency, request.Amount.Decimal(currency.Scale),
+		time.Duration(request.TtlSeconds)*time.Second)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	created, err := h.service.CreateHold(ctx, *hold)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, toHoldDto(created))
+}
+
+func (h *WalletHandler) GetHold(ctx *gin.Context) {
+
+	holdID, ok := holdIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.GetHold(ctx, holdID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toHoldDto(hold))
+}
+
+func (h *WalletHandler) CaptureHold(ctx *gin.Context) {
+
+	holdID, ok := holdIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.CaptureRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	capture, err := parseHoldCapture(request)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	hold, err := h.service.CaptureHold(ctx, holdID, *capture)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toHoldDto(hold))
+}
+
+func (h *WalletHandler) VoidHold(ctx *gin.Context) {
+
+	holdID, ok := holdIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.VoidHold(ctx, holdID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toHoldDto(hold))
+}
+
+func holdIDParam(ctx *gin.Context) (string, bool) {
+
+	holdID := ctx.Param("id")
+	tracing.Annotate(ctx, tracing.HoldID(holdID))
+	if _, err := uuid.Parse(holdID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "hold ID must be uuid"})
+		return "", false
+	}
+
+	return holdID, true
+}
+
+func parseHoldCapture(request dto.CaptureRequest) (*services.HoldCapture, error) {
+
+	if request.Amount == nil {
+		return services.NewHoldCapture(nil, nil)
+	}
+
+	currency, err := money.ParseCurrency(request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := request.Amount.Decimal(currency.Scale)
+	return services.NewHoldCapture(&currency, &amount)
+}
+
+func toHoldDto(hold entities.Hold) dto.Hold {
+
+	response := dto.Hold{
+		ID:            hold.ID,
+		WalletID:      hold.WalletID,
+		Amount:        money.FormatCode(hold.Amount, hold.Currency),
+		Currency:      hold.Currency,
+		Status:        string(hold.Status),
+		TransactionID: hold.TransactionID,
+		ExpiresAt:     hold.ExpiresAt,
+		CreatedAt:     hold.CreatedAt,
+	}
+
+	if hold.CapturedAmount != nil {
+		captured := money.FormatCode(*hold.CapturedAmount, hold.Currency)
+		response.CapturedAmount = &captured
+	}
+
+	return response
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"test-task/internal/dto"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+	"test-task/internal/services"
+)
+
+func (h *WalletHandler) CreateWallet(ctx *gin.Context) {
+
+	var request dto.CreateWalletRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	currency, err := money.ParseCurrency(request.Currency)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	initialBalance := decimal.Zero
+	if request.InitialBalance != nil {
+		initialBalance = request.InitialBalance.Decimal(currency.Scale)
+	}
+
+	creation, err := services.NewWalletCreation(request.OwnerRef, currency, initialBalance)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wallet, err := h.service.CreateWallet(ctx, *creation)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, toWalletDto(wallet))
+}
+
+func (h *WalletHandler) ListWallets(ctx *gin.Context) {
+
+	var query dto.WalletListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter, err := parseWalletFilter(query)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page, err := h.service.ListWallets(ctx, filter)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	response := dto.WalletPage{
+		Wallets:    make([]dto.Wallet, 0, len(page.Items)),
+		NextCursor: encodeCursor(page.Next),
+	}
+	for _, wallet := range page.Items {
+		response.Wallets = append(response.Wallets, toWalletDto(wallet))
+	}
+
+	ctx.JSON(200, response)
+}
+
+func parseWalletFilter(query dto.WalletListQuery) (entities.WalletFilter, error) {
+
+	filter := entities.WalletFilter{OwnerRef: query.OwnerRef, Limit: query.Limit}
+
+	if query.Limit < 0 {
+		return filter, fmt.Errorf("limit must not be negative")
+	}
+
+	if query.Status != "" {
+		status, err := services.ParseWalletStatus(query.Status)
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = status
+	}
+
+	if query.Currency != "" {
+		currency, err := money.ParseCurrency(query.Currency)
+		if err != nil {
+			return filter, err
+		}
+		filter.Currency = currency.Code
+	}
+
+	if query.MinBalance != "" {
+		balance, err := decimal.NewFromString(query.MinBalance)
+		if err != nil {
+			return filter, fmt.Errorf("minBalance is not a valid decimal")
+		}
+		filter.MinBalance = &balance
+	}
+
+	if query.MaxBalance != "" {
+		balance, err := decimal.NewFromString(query.MaxBalance)
+		if err != nil {
+			return filter, fmt.Errorf("maxBalance is not a valid decimal")
+		}
+		filter.MaxBalance = &balance
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+func toWalletDto(wallet entities.Wallet) dto.Wallet {
+	return dto.Wallet{
+		ID:          wallet.ID,
+		Balance:     money.FormatCode(wallet.Balance, wallet.Currency),
+		Available:   money.FormatCode(wallet.Available(), wallet.Currency),
+		CreditLimit: money.FormatCode(wallet.CreditLimit, wallet.Currency),
+		Currency:    wallet.Currency,
+		OwnerRef:    wallet.OwnerRef,
+		OwnerID:     wallet.OwnerID,
+		Status:      string(wallet.Status),
+		CreatedAt:   wallet.CreatedAt,
+		WithdrawalLimits: dto.WithdrawalLimits{
+			PerOperation: formatLimit(wallet.PerOperationWithdrawalLimit, wallet.Currency),
+			Daily:        formatLimit(wallet.DailyWithdrawalLimit, wallet.Currency),
+			Monthly:      formatLimit(wallet.MonthlyWithdrawalLimit, wallet.Currency),
+		},
+	}
+}
+
+func formatLimit(limit *decimal.Decimal, currency string) *string {
+	if limit == nil {
+		return nil
+	}
+	formatted := money.FormatCode(*limit, currency)
+	return &formatted
+}
@@ -2,15 +2,32 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"test-task/internal/dto"
+	"test-task/internal/entities"
+	"test-task/internal/logging"
+	"test-task/internal/money"
 	"test-task/internal/services"
+	"test-task/internal/tracing"
+	"time"
 )
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type walletService interface {
-	GetBalance(ctx context.Context, id string) (float64, error)
-	RunOperation(ctx context.Context, operation services.WalletOperation) error
+	GetBalance(ctx context.Context, id string) (entities.Wallet, error)
+	RunOperation(ctx context.Context, operation services.WalletOperation) (entities.Transaction, error)
+	GetTransactions(ctx context.Context, filter entities.TransactionFilter) (entities.Page[entities.Transaction], error)
+	Transfer(ctx context.Context, transfer services.WalletTransfer) (entities.Transfer, error)
+	CreateWallet(ctx context.Context, creation services.WalletCreation) (entities.Wallet, error)
+	ListWallets(ctx context.Context, filter entities.WalletFilter) (entities.Page[entities.Wallet], error)
+	CreateHold(ctx context.Context, hold services.WalletHold) (entities.Hold, error)
+	GetHold(ctx context.Context, id string) (entities.Hold, error)
+	CaptureHold(ctx context.Context, id string, capture services.HoldCapture) (entities.Hold, error)
+	VoidHold(ctx context.Context, id string) (entities.Hold, error)
 }
 
 type WalletHandler struct {
@@ -24,6 +41,7 @@ func NewWalletHandler(service walletService) *WalletHandler {
 func (h *WalletHandler) GetBalance(ctx *gin.Context) {
 
 	walletID := ctx.Param("id")
+	annotateWallet(ctx, walletID)
 
 	if walletID == "" {
 		ctx.JSON(400, dto.ErrorResponse{Error: "wallet ID is required"})
@@ -35,13 +53,17 @@ func (h *WalletHandler) GetBalance(ctx *gin.Context) {
 		return
 	}
 
-	balance, err := h.service.GetBalance(ctx, walletID)
+	wallet, err := h.service.GetBalance(ctx, walletID)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, dto.WalletBalance{Balance: balance})
+	ctx.JSON(200, dto.WalletBalance{
+		Balance:   money.FormatCode(wallet.Balance, wallet.Currency),
+		Available: money.FormatCode(wallet.Available(), wallet.Currency),
+		Currency:  wallet.Currency,
+	})
 }
 
 func (h *WalletHandler) RunOperation(ctx *gin.Context) {
@@ -51,16 +73,201 @@ func (h *WalletHandler) RunOperation(ctx *gin.Context) {
 		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	annotateWallet(ctx, dtoOp.WalledID)
+	tracing.Annotate(ctx, tracing.Operation(dtoOp.OperationType))
+
+	currency, err := money.ParseCurrency(dtoOp.Currency)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	op, err := services.NewWalletOperation(dtoOp.WalledID, dtoOp.OperationType, currency,
+		dtoOp.Amount.Decimal(currency.Scale))
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if key := ctx.GetHeader(idempotencyKeyHeader); key != "" {
+		if err := op.WithIdempotencyKey(key); err != nil {
+			ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	transaction, err := h.service.RunOperation(ctx, *op)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, toTransactionDto(transaction))
+}
+
+func (h *WalletHandler) GetTransactions(ctx *gin.Context) {
+
+	walletID := ctx.Param("id")
+	annotateWallet(ctx, walletID)
+
+	if _, err := uuid.Parse(walletID); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: "wallet ID must be uuid"})
+		return
+	}
+
+	var query dto.TransactionHistoryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter, err := parseTransactionFilter(walletID, query)
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page, err := h.service.GetTransactions(ctx, filter)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	response := dto.TransactionPage{
+		Transactions: make([]dto.Transaction, 0, len(page.Items)),
+		NextCursor:   encodeLedgerCursor(page.Next),
+	}
+	for _, transaction := range page.Items {
+		response.Transactions = append(response.Transactions, toTransactionDto(transaction))
+	}
+
+	ctx.JSON(200, response)
+}
+
+func (h *WalletHandler) Transfer(ctx *gin.Context) {
+
+	var request dto.TransferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	annotateWallet(ctx, request.FromWalletID)
+	tracing.Annotate(ctx, tracing.Transfer(request.FromWalletID, request.ToWalletID)...)
 
-	op, err := services.NewWalletOperation(dtoOp.WalledID, dtoOp.OperationType, dtoOp.Amount)
+	currency, err := money.ParseCurrency(request.Currency)
 	if err != nil {
 		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	err = h.service.RunOperation(ctx, *op)
+	transfer, err := services.NewWalletTransfer(request.FromWalletID, request.ToWalletID, currency,
+		request.Amount.Decimal(currency.Scale))
+	if err != nil {
+		ctx.JSON(400, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.service.Transfer(ctx, *transfer)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
+
+	ctx.JSON(200, dto.Transfer{
+		ID:           result.ID,
+		FromWalletID: result.FromWalletID,
+		ToWalletID:   result.ToWalletID,
+		Amount:       money.FormatCode(result.Amount, result.Currency),
+		Currency:     result.Currency,
+		ToAmount:     money.FormatCode(result.ToAmount, result.ToCurrency),
+		ToCurrency:   result.ToCurrency,
+		FxRate:       formatRate(result.FxRate),
+		CreatedAt:    result.CreatedAt,
+	})
+}
+
+// annotateWallet tags the request's span and access log line with the wallet it acts on.
+func annotateWallet(ctx *gin.Context, walletID string) {
+	tracing.Annotate(ctx, tracing.WalletID(walletID))
+	ctx.Set(logging.WalletIDKey, walletID)
+}
+
+func parseTransactionFilter(walletID string, query dto.TransactionHistoryQuery) (entities.TransactionFilter, error) {
+
+	filter := entities.TransactionFilter{WalletID: walletID, Limit: query.Limit}
+
+	if query.Limit < 0 {
+		return filter, fmt.Errorf("limit must not be negative")
+	}
+
+	if query.OperationType != "" {
+		if !services.IsTransactionType(query.OperationType) {
+			return filter, fmt.Errorf("invalid operation type: %s", query.OperationType)
+		}
+		filter.OperationType = query.OperationType
+	}
+
+	if query.MinAmount != "" {
+		amount, err := decimal.NewFromString(query.MinAmount)
+		if err != nil {
+			return filter, fmt.Errorf("minAmount is not a valid decimal")
+		}
+		filter.MinAmount = &amount
+	}
+
+	if query.MaxAmount != "" {
+		amount, err := decimal.NewFromString(query.MaxAmount)
+		if err != nil {
+			return filter, fmt.Errorf("maxAmount is not a valid decimal")
+		}
+		filter.MaxAmount = &amount
+	}
+
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return filter, fmt.Errorf("from must be an RFC 3339 timestamp")
+		}
+		filter.From = &from
+	}
+
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return filter, fmt.Errorf("to must be an RFC 3339 timestamp")
+		}
+		filter.To = &to
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeLedgerCursor(query.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+func toTransactionDto(transaction entities.Transaction) dto.Transaction {
+	return dto.Transaction{
+		ID:            transaction.ID,
+		WalletID:      transaction.WalletID,
+		OperationType: transaction.OperationType,
+		Amount:        money.FormatCode(transaction.Amount, transaction.Currency),
+		Currency:      transaction.Currency,
+		BalanceAfter:  money.FormatCode(transaction.BalanceAfter, transaction.Currency),
+		CreatedAt:     transaction.CreatedAt,
+		TransferID:    transaction.TransferID,
+		FxRate:        formatRate(transaction.FxRate),
+	}
+}
+
+func formatRate(rate *decimal.Decimal) *string {
+	if rate == nil {
+		return nil
+	}
+	formatted := rate.String()
+	return &formatted
 }
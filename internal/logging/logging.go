@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+)
+
+type requestIDKey struct{}
+
+// WalletIDKey is the gin context key handlers store the wallet a request
+// acts on under, for the access log.
+const WalletIDKey = "wallet_id"
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns a logger tagged with the request ID carried by ctx, if any.
+func FromContext(ctx context.Context) *log.Entry {
+	entry := log.NewEntry(log.StandardLogger())
+	if requestID := RequestID(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	return entry
+}
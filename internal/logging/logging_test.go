@@ -0,0 +1,15 @@
+package logging
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFromContext_ShouldTagRequestID(t *testing.T) {
+
+	assert.NotContains(t, FromContext(context.Background()).Data, "request_id")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", FromContext(ctx).Data["request_id"])
+}
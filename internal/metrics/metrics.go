@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	errs "test-task/internal/errors"
+	"time"
+)
+
+const namespace = "wallet"
+
+const (
+	OutcomeSuccess             = "success"
+	OutcomeInsufficientBalance = "insufficient_balance"
+	OutcomeNotFound            = "not_found"
+	OutcomeRateLimited         = "rate_limited"
+	OutcomeError               = "error"
+)
+
+var operations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "operations_total",
+	Help:      "Wallet operations by type and outcome.",
+}, []string{"operation", "outcome"})
+
+var rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "rate_limited_total",
+	Help:      "Requests rejected by the rate limiter by access kind.",
+}, []string{"access"})
+
+var requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "http_request_duration_seconds",
+	Help:      "HTTP request latency by route.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// Outcome names the result of an operation for the outcome label.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, errs.InsufficientBalance):
+		return OutcomeInsufficientBalance
+	case errors.Is(err, errs.NotFound):
+		return OutcomeNotFound
+	case errors.Is(err, errs.TooManyRequests):
+		return OutcomeRateLimited
+	default:
+		return OutcomeError
+	}
+}
+
+func ObserveOperation(operation string, err error) {
+	operations.WithLabelValues(operation, Outcome(err)).Inc()
+}
+
+func ObserveRateLimited(access string) {
+	rateLimited.WithLabelValues(access).Inc()
+}
+
+func ObserveRequest(method string, route string, status string, duration time.Duration) {
+	requestDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// RegisterLimiterKeys exposes how many buckets the in-memory limiter holds.
+func RegisterLimiterKeys(size func() int) error {
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rate_limiter_keys",
+		Help:      "Token buckets held by the in-memory rate limiter.",
+	}, func() float64 {
+		return float64(size())
+	}))
+}
+
+// RegisterDB exposes the pool stats of db labelled with name.
+func RegisterDB(name string, db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
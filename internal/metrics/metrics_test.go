@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	errs "test-task/internal/errors"
+	"testing"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, Outcome(nil))
+	assert.Equal(t, OutcomeInsufficientBalance, Outcome(errs.InsufficientBalance))
+	assert.Equal(t, OutcomeNotFound, Outcome(fmt.Errorf("wallet %s: %w", "id", errs.NotFound)))
+	assert.Equal(t, OutcomeRateLimited, Outcome(&errs.RateLimited{Limit: 5}))
+	assert.Equal(t, OutcomeError, Outcome(fmt.Errorf("connection refused")))
+}
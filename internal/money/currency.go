@@ -0,0 +1,64 @@
+package money
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency with its number of minor-unit digits.
+type Currency struct {
+	Code  string
+	Scale int32
+}
+
+// storageScale matches the NUMERIC(20, 4) columns amounts are stored in.
+const storageScale int32 = 4
+
+var currencies = map[string]Currency{
+	"USD": {"USD", 2},
+	"EUR": {"EUR", 2},
+	"GBP": {"GBP", 2},
+	"CHF": {"CHF", 2},
+	"CNY": {"CNY", 2},
+	"RUB": {"RUB", 2},
+	"KZT": {"KZT", 2},
+	"JPY": {"JPY", 0},
+	"KRW": {"KRW", 0},
+	"KWD": {"KWD", 3},
+	"BHD": {"BHD", 3},
+}
+
+func ParseCurrency(code string) (Currency, error) {
+
+	if code == "" {
+		return Currency{}, fmt.Errorf("currency is empty")
+	}
+
+	currency, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("unsupported currency: %s", code)
+	}
+
+	return currency, nil
+}
+
+// FormatCode formats an amount stored for the given currency code,
+// falling back to the storage scale for codes no longer supported.
+func FormatCode(amount decimal.Decimal, code string) string {
+	if currency, err := ParseCurrency(code); err == nil {
+		return currency.Format(amount)
+	}
+	return Format(amount, storageScale)
+}
+
+func (c Currency) Format(amount decimal.Decimal) string {
+	return Format(amount, c.Scale)
+}
+
+func (c Currency) Validate(amount decimal.Decimal) error {
+	if !HasScale(amount, c.Scale) {
+		return fmt.Errorf("%s amounts must have at most %d decimal places", c.Code, c.Scale)
+	}
+	return nil
+}
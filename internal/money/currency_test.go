@@ -0,0 +1,35 @@
+package money
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCurrency_ValidateRespectsMinorUnits(t *testing.T) {
+
+	assert := assert.New(t)
+
+	jpy, err := ParseCurrency("jpy")
+	assert.NoError(err)
+	assert.NoError(jpy.Validate(decimal.RequireFromString("100")))
+	assert.Error(jpy.Validate(decimal.RequireFromString("100.5")))
+
+	kwd, err := ParseCurrency("KWD")
+	assert.NoError(err)
+	assert.NoError(kwd.Validate(decimal.RequireFromString("1.125")))
+	assert.Error(kwd.Validate(decimal.RequireFromString("1.1255")))
+}
+
+func TestCurrency_ParseUnsupported(t *testing.T) {
+
+	_, err := ParseCurrency("XYZ")
+	assert.Error(t, err)
+}
+
+func TestFormatCode(t *testing.T) {
+
+	assert.Equal(t, "10.50", FormatCode(decimal.RequireFromString("10.5"), "USD"))
+	assert.Equal(t, "10", FormatCode(decimal.RequireFromString("10"), "JPY"))
+	assert.Equal(t, "10.5000", FormatCode(decimal.RequireFromString("10.5"), "XYZ"))
+}
@@ -0,0 +1,15 @@
+package money
+
+import "github.com/shopspring/decimal"
+
+func HasScale(amount decimal.Decimal, scale int32) bool {
+	return amount.Equal(amount.Truncate(scale))
+}
+
+func FromMinorUnits(units int64, scale int32) decimal.Decimal {
+	return decimal.New(units, -scale)
+}
+
+func Format(amount decimal.Decimal, scale int32) string {
+	return amount.StringFixed(scale)
+}
@@ -0,0 +1,37 @@
+package money
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+type RoundingMode string
+
+const (
+	RoundHalfEven RoundingMode = "half_even"
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundDown     RoundingMode = "down"
+	RoundUp       RoundingMode = "up"
+)
+
+func ParseRoundingMode(value string) (RoundingMode, error) {
+	switch mode := RoundingMode(value); mode {
+	case RoundHalfEven, RoundHalfUp, RoundDown, RoundUp:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid rounding mode: %s", value)
+	}
+}
+
+func (m RoundingMode) Round(amount decimal.Decimal, scale int32) decimal.Decimal {
+	switch m {
+	case RoundHalfUp:
+		return amount.Round(scale)
+	case RoundDown:
+		return amount.RoundDown(scale)
+	case RoundUp:
+		return amount.RoundUp(scale)
+	default:
+		return amount.RoundBank(scale)
+	}
+}
@@ -0,0 +1,29 @@
+package money
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoundingMode_Round(t *testing.T) {
+
+	amount := decimal.RequireFromString("2.345")
+
+	cases := map[RoundingMode]string{
+		RoundHalfEven: "2.34",
+		RoundHalfUp:   "2.35",
+		RoundDown:     "2.34",
+		RoundUp:       "2.35",
+	}
+
+	for mode, expected := range cases {
+		assert.Equal(t, expected, mode.Round(amount, 2).StringFixed(2), string(mode))
+	}
+}
+
+func TestParseRoundingMode_Invalid(t *testing.T) {
+
+	_, err := ParseRoundingMode("nearest")
+	assert.Error(t, err)
+}
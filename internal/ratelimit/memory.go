@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"golang.org/x/time/rate"
+	"hash/maphash"
+	"sync"
+	"test-task/internal/entities"
+	"time"
+)
+
+const shardCount = 64
+
+type keyLimiter struct {
+	key        string
+	limiter    *rate.Limiter
+	lastAccess time.Time
+}
+
+// shard keeps its limiters in a list ordered by last access, most recent first,
+// so both capacity and idle eviction only ever look at the tail.
+type shard struct {
+	mu       sync.Mutex
+	limiters map[string]*list.Element
+	recency  *list.List
+	capacity int
+}
+
+// Memory keeps a token bucket per key in process, so every instance
+// enforces its own limit. Keys are spread over shards with their own lock,
+// and each shard holds at most maxKeys/shardCount buckets. A full shard
+// drops its least recently used bucket that has refilled to burst, so a flood
+// of new keys cannot reset the bucket of a key that is still being limited;
+// when every bucket still holds debt, the new key waits for one to refill.
+type Memory struct {
+	seed   maphash.Seed
+	shards [shardCount]*shard
+}
+
+func NewMemory(maxKeys int) *Memory {
+	capacity := (maxKeys + shardCount - 1) / shardCount
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	m := &Memory{seed: maphash.MakeSeed()}
+	for i := range m.shards {
+		m.shards[i] = &shard{
+			limiters: make(map[string]*list.Element),
+			recency:  list.New(),
+			capacity: capacity,
+		}
+	}
+	return m
+}
+
+func (m *Memory) Allow(_ context.Context, key string,
+	policy entities.RateLimitPolicy) (entities.RateLimitDecision, error) {
+
+	now := time.Now()
+	limiter, retryAfter := m.shardFor(key).get(key, policy, now)
+	if limiter == nil {
+		return entities.RateLimitDecision{RetryAfter: retryAfter}, nil
+	}
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return entities.RateLimitDecision{}, nil
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return entities.RateLimitDecision{RetryAfter: delay}, nil
+	}
+
+	return entities.RateLimitDecision{Allowed: true}, nil
+}
+
+// Cleanup drops the buckets of keys idle for longer than idle.
+func (m *Memory) Cleanup(_ context.Context, idle time.Duration) error {
+	cutoff := time.Now().Add(-idle)
+	for _, s := range m.shards {
+		s.evictIdle(cutoff)
+	}
+	return nil
+}
+
+// Len returns the number of buckets currently kept.
+func (m *Memory) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		n += s.recency.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func (m *Memory) shardFor(key string) *shard {
+	return m.shards[maphash.String(m.seed, key)%shardCount]
+}
+
+// get returns the key's limiter, or how long to wait when the shard is full
+// and has no bucket it may evict.
+func (s *shard) get(key string, policy entities.RateLimitPolicy, now time.Time) (*rate.Limiter, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.limiters[key]; ok {
+		entry := element.Value.(*keyLimiter)
+		entry.lastAccess = now
+		s.recency.MoveToFront(element)
+
+		if entry.limiter.Limit() != rate.Limit(policy.Rate) || entry.limiter.Burst() != policy.Burst {
+			entry.limiter.SetLimitAt(now, rate.Limit(policy.Rate))
+			entry.limiter.SetBurstAt(now, policy.Burst)
+		}
+		return entry.limiter, 0
+	}
+
+	if s.recency.Len() >= s.capacity {
+		victim := s.refilled(now)
+		if victim == nil {
+			return nil, refillDelay(s.recency.Back().Value.(*keyLimiter).limiter, now)
+		}
+		s.remove(victim)
+	}
+
+	entry := &keyLimiter{key: key, limiter: rate.NewLimiter(rate.Limit(policy.Rate), policy.Burst), lastAccess: now}
+	s.limiters[key] = s.recency.PushFront(entry)
+	return entry.limiter, 0
+}
+
+// refilled returns the least recently used bucket back at its burst, whose
+// key would get the same decisions from a fresh bucket.
+func (s *shard) refilled(now time.Time) *list.Element {
+	for element := s.recency.Back(); element != nil; element = element.Prev() {
+		if refillDelay(element.Value.(*keyLimiter).limiter, now) == 0 {
+			return element
+		}
+	}
+	return nil
+}
+
+func refillDelay(limiter *rate.Limiter, now time.Time) time.Duration {
+	debt := float64(limiter.Burst()) - limiter.TokensAt(now)
+	if debt <= 0 || limiter.Limit() == rate.Inf {
+		return 0
+	}
+	return time.Duration(debt / float64(limiter.Limit()) * float64(time.Second))
+}
+
+func (s *shard) evictIdle(cutoff time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for element := s.recency.Back(); element != nil; element = s.recency.Back() {
+		if element.Value.(*keyLimiter).lastAccess.After(cutoff) {
+			return
+		}
+		s.remove(element)
+	}
+}
+
+func (s *shard) remove(element *list.Element) {
+	delete(s.limiters, element.Value.(*keyLimiter).key)
+	s.recency.Remove(element)
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync/atomic"
+	"test-task/internal/entities"
+	"testing"
+	"time"
+)
+
+func TestMemory_Allow_ShouldLimitEachKeyToBurst(t *testing.T) {
+
+	limiter := NewMemory(100)
+	ctx := context.Background()
+	policy := entities.RateLimitPolicy{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		decision, err := limiter.Allow(ctx, "a", policy)
+		assert.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+
+	decision, _ := limiter.Allow(ctx, "a", policy)
+	assert.False(t, decision.Allowed)
+	assert.Positive(t, decision.RetryAfter)
+
+	decision, _ = limiter.Allow(ctx, "b", policy)
+	assert.True(t, decision.Allowed)
+}
+
+func TestMemory_Cleanup_ShouldKeepRecentlyUsedKeys(t *testing.T) {
+
+	limiter := NewMemory(100)
+	ctx := context.Background()
+	policy := entities.RateLimitPolicy{Rate: 1, Burst: 1}
+
+	_, _ = limiter.Allow(ctx, "idle", policy)
+	_, _ = limiter.Allow(ctx, "hot", policy)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = limiter.Allow(ctx, "hot", policy)
+
+	assert.NoError(t, limiter.Cleanup(ctx, 10*time.Millisecond))
+	assert.Equal(t, 1, limiter.Len())
+
+	decision, _ := limiter.Allow(ctx, "hot", policy)
+	assert.False(t, decision.Allowed, "hot key must keep its drained bucket")
+}
+
+func TestMemory_Allow_ShouldBoundKeys(t *testing.T) {
+
+	limiter := NewMemory(shardCount)
+	policy := entities.RateLimitPolicy{Rate: 1, Burst: 1}
+
+	for i := 0; i < 10*shardCount; i++ {
+		_, _ = limiter.Allow(context.Background(), strconv.Itoa(i), policy)
+	}
+
+	assert.LessOrEqual(t, limiter.Len(), shardCount)
+}
+
+func TestMemory_Allow_WhenFloodedWithNewKeys_ShouldKeepDrainedBuckets(t *testing.T) {
+
+	limiter := NewMemory(shardCount)
+	ctx := context.Background()
+	policy := entities.RateLimitPolicy{Rate: 0.01, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		decision, _ := limiter.Allow(ctx, "hot", policy)
+		assert.True(t, decision.Allowed)
+	}
+
+	flood := entities.RateLimitPolicy{Rate: 1000, Burst: 1}
+	for i := 0; i < 10*shardCount; i++ {
+		_, _ = limiter.Allow(ctx, strconv.Itoa(i), flood)
+	}
+
+	decision, _ := limiter.Allow(ctx, "hot", policy)
+	assert.False(t, decision.Allowed, "hot key must keep its drained bucket")
+	assert.LessOrEqual(t, limiter.Len(), shardCount)
+}
+
+func BenchmarkMemory_Allow_SingleKey(b *testing.B) {
+
+	limiter := NewMemory(100000)
+	policy := entities.RateLimitPolicy{Rate: 1000, Burst: 5}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = limiter.Allow(context.Background(), "write:11111111-1111-1111-1111-111111111111", policy)
+		}
+	})
+}
+
+func BenchmarkMemory_Allow_ManyKeys(b *testing.B) {
+
+	limiter := NewMemory(100000)
+	policy := entities.RateLimitPolicy{Rate: 1000, Burst: 5}
+	var next atomic.Int64
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			key := "write:" + strconv.FormatInt(next.Add(1)%50000, 10)
+			_, _ = limiter.Allow(context.Background(), key, policy)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const apiKeyColumns = "id, name, key_hash, scopes, owner_id, admin, created_at, revoked_at"
+
+type ApiKeys struct {
+	db *sqlx.DB
+}
+
+func NewApiKeysRepository(db *sqlx.DB) *ApiKeys {
+	return &ApiKeys{db: db}
+}
+
+func (repo *ApiKeys) Create(ctx context.Context, key entities.ApiKey) (entities.ApiKey, error) {
+	var created entities.ApiKey
+	err := repo.db.GetContext(ctx, &created,
+		"INSERT INTO api_keys (name, key_hash, scopes, owner_id, admin) VALUES ($1, $2, $3, $4, $5) RETURNING "+apiKeyColumns,
+		key.Name, key.KeyHash, key.Scopes, key.OwnerID, key.Admin)
+	return created, err
+}
+
+// GetActiveByHash finds a key that has not been revoked.
+func (repo *ApiKeys) GetActiveByHash(ctx context.Context, hash string) (entities.ApiKey, error) {
+	var key entities.ApiKey
+	err := repo.db.GetContext(ctx, &key,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, fmt.Errorf("%w: api key", errs.NotFound)
+	}
+	return key, err
+}
+
+// Revoke marks the key revoked, keeping the original time for a key already revoked.
+func (repo *ApiKeys) Revoke(ctx context.Context, id string) (entities.ApiKey, error) {
+	var key entities.ApiKey
+	err := repo.db.GetContext(ctx, &key,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1 RETURNING "+apiKeyColumns, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, fmt.Errorf("%w: api key by id %s", errs.NotFound, id)
+	}
+	return key, err
+}
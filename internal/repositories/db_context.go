@@ -1,16 +1,41 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"test-task/internal/entities"
 	"time"
 )
 
 type DbContext struct {
-	DB *sqlx.DB
+	DB       *sqlx.DB
+	Replicas []*sqlx.DB
 }
 
-func NewDbContext(connectionString string) (*DbContext, error) {
+func NewDbContext(connectionString string, replicaConnectionStrings ...string) (*DbContext, error) {
+
+	db, err := connect(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	dbContext := &DbContext{DB: db}
+	for _, replicaConnectionString := range replicaConnectionStrings {
+		replica, err := connect(replicaConnectionString)
+		if err != nil {
+			_ = dbContext.Close()
+			return nil, err
+		}
+		dbContext.Replicas = append(dbContext.Replicas, replica)
+	}
+
+	return dbContext, nil
+}
+
+func connect(connectionString string) (*sqlx.DB, error) {
 
 	db, err := sqlx.Connect("postgres", connectionString)
 	if err != nil {
@@ -21,13 +46,42 @@ func NewDbContext(connectionString string) (*DbContext, error) {
 	db.SetMaxOpenConns(20)
 	db.SetConnMaxLifetime(30 * time.Minute)
 
-	return &DbContext{DB: db}, nil
+	return db, nil
 }
 
 func (c *DbContext) Migrate() error {
 	return runMigrations(c.DB.DB)
 }
 
+// Health pings the primary and reports its schema version and pool usage.
+func (c *DbContext) Health(ctx context.Context) (entities.DbHealth, error) {
+
+	if err := c.DB.PingContext(ctx); err != nil {
+		return entities.DbHealth{}, err
+	}
+
+	stats := c.DB.Stats()
+	health := entities.DbHealth{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		WaitCount:          stats.WaitCount,
+	}
+
+	err := c.DB.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&health.Version, &health.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return health, err
+	}
+
+	health.ExpectedVersion, err = latestMigrationVersion()
+	return health, err
+}
+
 func (c *DbContext) Close() error {
-	return c.DB.Close()
+	errs := []error{c.DB.Close()}
+	for _, replica := range c.Replicas {
+		errs = append(errs, replica.Close())
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+type FxRates struct {
+	db *sqlx.DB
+}
+
+func NewFxRatesRepository(db *sqlx.DB) *FxRates {
+	return &FxRates{db: db}
+}
+
+func (repo *FxRates) Get(ctx context.Context, base string, quote string) (entities.FxRate, error) {
+	var rate entities.FxRate
+	err := repo.db.GetContext(ctx, &rate,
+		"SELECT * FROM fx_rates WHERE base_currency = $1 AND quote_currency = $2", base, quote)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return rate, fmt.Errorf("%w: fx rate %s/%s", errs.NotFound, base, quote)
+		}
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+func (repo *FxRates) Upsert(ctx context.Context, rate entities.FxRate) (entities.FxRate, error) {
+	err := repo.db.GetContext(ctx, &rate,
+		`INSERT INTO fx_rates (base_currency, quote_currency, rate) VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE SET rate = EXCLUDED.rate, updated_at = now()
+		RETURNING *`,
+		rate.BaseCurrency, rate.QuoteCurrency, rate.Rate)
+	return rate, err
+}
+
+func (repo *FxRates) List(ctx context.Context) ([]entities.FxRate, error) {
+	rates := []entities.FxRate{}
+	err := repo.db.SelectContext(ctx, &rates, "SELECT * FROM fx_rates ORDER BY base_currency, quote_currency")
+	return rates, err
+}
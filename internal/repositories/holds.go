@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+	"test-task/internal/tracing"
+	"time"
+)
+
+// CreateHold reserves the hold's amount. The reservation is checked against
+// the withdrawal limits up front; CaptureHold checks them again, since only
+// the capture counts towards the spent amount.
+func (repo *Wallets) CreateHold(ctx context.Context, hold entities.Hold,
+	limits entities.CurrencyWithdrawalLimits) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.CreateHold", tracing.WalletID(hold.WalletID))
+	defer tracing.End(span, &err)
+
+	err = withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		if err := checkWithdrawalLimits(ctx, tx, hold.WalletID, hold.Amount, limits); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx,
+			`UPDATE wallets SET held = held + $1
+			WHERE id = $2 AND status = ANY($3) AND currency = $4`,
+			hold.Amount, hold.WalletID, pq.Array(allowedStatuses(hold.Amount.Neg())), hold.Currency)
+		if err != nil {
+			if isCreditLimitViolation(err) {
+				return errs.InsufficientBalance
+			}
+			return err
+		}
+
+		if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+			if err != nil {
+				return err
+			}
+			return unavailableWalletError(ctx, tx, hold.WalletID, hold.Currency)
+		}
+
+		return tx.GetContext(ctx, &hold,
+			`INSERT INTO holds (wallet_id, currency, amount, expires_at) VALUES ($1, $2, $3, $4) RETURNING *`,
+			hold.WalletID, hold.Currency, hold.Amount, hold.ExpiresAt)
+	})
+
+	return hold, err
+}
+
+// CaptureHold debits amount (the full hold when nil) and releases the whole
+// reservation, so a partial capture settles the hold. currency, when set,
+// must match the hold. The captured amount is a debit and must fit the
+// withdrawal limits.
+func (repo *Wallets) CaptureHold(ctx context.Context, id string, currency string,
+	amount *decimal.Decimal, limits entities.CurrencyWithdrawalLimits) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.CaptureHold", tracing.HoldID(id))
+	defer tracing.End(span, &err)
+
+	var hold entities.Hold
+
+	err = withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		var err error
+		if hold, err = lockActiveHold(ctx, tx, id); err != nil {
+			return err
+		}
+
+		if currency != "" && currency != hold.Currency {
+			return fmt.Errorf("%w: hold %s is in %s, not %s", errs.CurrencyMismatch, id, hold.Currency, currency)
+		}
+
+		captured := hold.Amount
+		if amount != nil {
+			captured = *amount
+		}
+		if captured.GreaterThan(hold.Amount) {
+			return fmt.Errorf("%w: capture of %s exceeds hold of %s", errs.InsufficientBalance, captured, hold.Amount)
+		}
+
+		if err := checkWithdrawalLimits(ctx, tx, hold.WalletID, captured, limits); err != nil {
+			return err
+		}
+
+		if err := releaseHold(ctx, tx, hold); err != nil {
+			return err
+		}
+
+		transaction, err := applyDelta(ctx, tx, entities.Transaction{
+			WalletID:      hold.WalletID,
+			Currency:      hold.Currency,
+			OperationType: entities.Capture,
+		}, captured.Neg())
+		if err != nil {
+			return err
+		}
+
+		return tx.GetContext(ctx, &hold,
+			`UPDATE holds SET status = $1, captured_amount = $2, transaction_id = $3, updated_at = now()
+			WHERE id = $4 RETURNING *`,
+			entities.HoldCaptured, captured, transaction.ID, hold.ID)
+	})
+
+	return hold, err
+}
+
+func (repo *Wallets) VoidHold(ctx context.Context, id string) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.VoidHold", tracing.HoldID(id))
+	defer tracing.End(span, &err)
+
+	var hold entities.Hold
+
+	err = withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		var err error
+		if hold, err = lockActiveHold(ctx, tx, id); err != nil {
+			return err
+		}
+
+		if err := releaseHold(ctx, tx, hold); err != nil {
+			return err
+		}
+
+		return tx.GetContext(ctx, &hold,
+			"UPDATE holds SET status = $1, updated_at = now() WHERE id = $2 RETURNING *",
+			entities.HoldVoided, hold.ID)
+	})
+
+	return hold, err
+}
+
+func (repo *Wallets) ExpireHolds(ctx context.Context, now time.Time) (int64, error) {
+
+	var expired int64
+
+	err := withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &expired,
+			`WITH expired AS (
+				UPDATE holds SET status = $1, updated_at = now()
+				WHERE status = $2 AND expires_at <= $3
+				RETURNING wallet_id, amount
+			), released AS (
+				UPDATE wallets w SET held = w.held - e.total
+				FROM (SELECT wallet_id, SUM(amount) AS total FROM expired GROUP BY wallet_id) e
+				WHERE w.id = e.wallet_id
+			)
+			SELECT COUNT(*) FROM expired`,
+			entities.HoldExpired, entities.HoldActive, now)
+	})
+
+	return expired, err
+}
+
+func lockActiveHold(ctx context.Context, tx *sqlx.Tx, id string) (entities.Hold, error) {
+
+	var hold entities.Hold
+	err := tx.GetContext(ctx, &hold, "SELECT * FROM holds WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return hold, fmt.Errorf("%w: hold by id %s", errs.NotFound, id)
+		}
+		return hold, err
+	}
+
+	if hold.Status != entities.HoldActive || !hold.ExpiresAt.After(time.Now()) {
+		return hold, fmt.Errorf("%w: hold %s is %s", errs.HoldNotActive, id, hold.Status)
+	}
+
+	return hold, nil
+}
+
+func releaseHold(ctx context.Context, tx *sqlx.Tx, hold entities.Hold) error {
+	_, err := tx.ExecContext(ctx, "UPDATE wallets SET held = held - $1 WHERE id = $2", hold.Amount, hold.WalletID)
+	return err
+}
+
+func (repo *Wallets) GetHold(ctx context.Context, id string) (entities.Hold, error) {
+	var hold entities.Hold
+	err := repo.db.GetContext(ctx, &hold, "SELECT * FROM holds WHERE id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return hold, fmt.Errorf("%w: hold by id %s", errs.NotFound, id)
+		}
+		return hold, err
+	}
+
+	return hold, nil
+}
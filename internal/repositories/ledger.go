@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"strings"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const (
+	balanceCreditLimitCheck   = "check_balance_within_credit_limit"
+	availableCreditLimitCheck = "check_available_within_credit_limit"
+)
+
+func transactionColumns(alias string) string {
+	columns := []string{"id", "seq", "wallet_id", "operation_type", "amount", "balance_after", "created_at", "transfer_id", "currency",
+		"fx_rate"}
+	if alias != "" {
+		for i := range columns {
+			columns[i] = alias + "." + columns[i]
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// applyDelta changes the wallet balance and records the ledger entry described
+// by transaction, whose WalletID, Currency and OperationType must be set.
+func applyDelta(ctx context.Context, tx *sqlx.Tx, transaction entities.Transaction,
+	delta decimal.Decimal) (entities.Transaction, error) {
+
+	transaction.Amount = delta.Abs()
+
+	err := tx.GetContext(ctx, &transaction.BalanceAfter,
+		`UPDATE wallets SET balance = balance + $1
+		WHERE id = $2 AND status = ANY($3) AND currency = $4 RETURNING balance`,
+		delta, transaction.WalletID, pq.Array(allowedStatuses(delta)), transaction.Currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction, unavailableWalletError(ctx, tx, transaction.WalletID, transaction.Currency)
+		}
+		if isCreditLimitViolation(err) {
+			return transaction, errs.InsufficientBalance
+		}
+		return transaction, err
+	}
+
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO wallet_transactions (wallet_id, operation_type, amount, balance_after, transfer_id, currency, fx_rate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, seq, created_at`,
+		transaction.WalletID, transaction.OperationType, transaction.Amount, transaction.BalanceAfter,
+		transaction.TransferID, transaction.Currency, transaction.FxRate,
+	).Scan(&transaction.ID, &transaction.Seq, &transaction.CreatedAt)
+
+	return transaction, err
+}
+
+func isCreditLimitViolation(err error) bool {
+	return isCheckViolation(err, balanceCreditLimitCheck) || isCheckViolation(err, availableCreditLimitCheck)
+}
+
+func allowedStatuses(delta decimal.Decimal) []string {
+	var allowed []string
+	for _, status := range entities.WalletStatuses {
+		if status.Allows(delta) {
+			allowed = append(allowed, string(status))
+		}
+	}
+	return allowed
+}
+
+func unavailableWalletError(ctx context.Context, tx *sqlx.Tx, walletID string, currency string) error {
+
+	var wallet entities.Wallet
+	err := tx.GetContext(ctx, &wallet, "SELECT * FROM wallets WHERE id = $1", walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: wallet by id %s", errs.NotFound, walletID)
+		}
+		return err
+	}
+
+	if wallet.Currency != currency {
+		return fmt.Errorf("%w: wallet %s holds %s, not %s", errs.CurrencyMismatch, walletID, wallet.Currency, currency)
+	}
+
+	return fmt.Errorf("%w: wallet %s is %s", errs.WalletUnavailable, walletID, wallet.Status)
+}
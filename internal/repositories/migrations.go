@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	log "github.com/sirupsen/logrus"
+	"os"
 )
 
+const migrationsSource = "file://migrations"
+
 func runMigrations(db *sql.DB) error {
 
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
@@ -18,7 +22,7 @@ func runMigrations(db *sql.DB) error {
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
+		migrationsSource,
 		"postgres",
 		driver,
 	)
@@ -35,3 +39,29 @@ func runMigrations(db *sql.DB) error {
 	}
 	return nil
 }
+
+// latestMigrationVersion is the version the schema has once every migration is applied.
+func latestMigrationVersion() (uint, error) {
+
+	migrations, err := source.Open(migrationsSource)
+	if err != nil {
+		return 0, err
+	}
+	defer migrations.Close()
+
+	version, err := migrations.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := migrations.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+	foreignKeyViolation = "23503"
+)
+
+func isUniqueViolation(err error, constraint string) bool {
+	return isViolation(err, uniqueViolation, constraint)
+}
+
+func isCheckViolation(err error, constraint string) bool {
+	return isViolation(err, checkViolation, constraint)
+}
+
+func isForeignKeyViolation(err error, constraint string) bool {
+	return isViolation(err, foreignKeyViolation, constraint)
+}
+
+func isViolation(err error, code pq.ErrorCode, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code && pqErr.Constraint == constraint
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import "fmt"
+
+type queryBuilder struct {
+	query string
+	args  []any
+}
+
+func newQueryBuilder(query string, args ...any) *queryBuilder {
+	return &queryBuilder{query: query, args: args}
+}
+
+// where appends "AND condition", condition uses %d in place of each
+// positional parameter number.
+func (b *queryBuilder) where(condition string, values ...any) {
+	b.query += " AND " + fmt.Sprintf(condition, b.bind(values...)...)
+}
+
+func (b *queryBuilder) suffix(clause string, values ...any) {
+	b.query += " " + fmt.Sprintf(clause, b.bind(values...)...)
+}
+
+func (b *queryBuilder) bind(values ...any) []any {
+	placeholders := make([]any, len(values))
+	for i, value := range values {
+		b.args = append(b.args, value)
+		placeholders[i] = len(b.args)
+	}
+	return placeholders
+}
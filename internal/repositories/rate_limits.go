@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"test-task/internal/entities"
+	"time"
+)
+
+// RateLimits keeps token buckets in Postgres, so the limit of a key
+// holds across every instance sharing the database.
+type RateLimits struct {
+	db *sqlx.DB
+}
+
+func NewRateLimitsRepository(db *sqlx.DB) *RateLimits {
+	return &RateLimits{db: db}
+}
+
+// Allow refills the bucket for the time elapsed since it was last touched
+// and takes a token from it, in a single statement.
+func (repo *RateLimits) Allow(ctx context.Context, key string,
+	policy entities.RateLimitPolicy) (entities.RateLimitDecision, error) {
+
+	if policy.Burst < 1 || policy.Rate <= 0 {
+		return entities.RateLimitDecision{}, nil
+	}
+
+	var tokens float64
+	err := repo.db.GetContext(ctx, &tokens,
+		`INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at) VALUES ($1, $3::float8 - 1, now())
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $2::float8) - 1,
+			updated_at = now()
+		WHERE LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $2::float8) >= 1
+		RETURNING tokens`,
+		key, policy.Rate, policy.Burst)
+	if err == nil {
+		return entities.RateLimitDecision{Allowed: true}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return entities.RateLimitDecision{}, err
+	}
+
+	err = repo.db.GetContext(ctx, &tokens,
+		`SELECT LEAST($3::float8, tokens + EXTRACT(EPOCH FROM now() - updated_at)::float8 * $2::float8)
+		FROM rate_limit_buckets WHERE key = $1`,
+		key, policy.Rate, policy.Burst)
+	if err != nil {
+		return entities.RateLimitDecision{}, err
+	}
+
+	retryAfter := time.Duration(math.Ceil((1 - tokens) / policy.Rate * float64(time.Second)))
+	return entities.RateLimitDecision{RetryAfter: retryAfter}, nil
+}
+
+// Cleanup drops the buckets of keys idle for longer than idle.
+func (repo *RateLimits) Cleanup(ctx context.Context, idle time.Duration) error {
+	_, err := repo.db.ExecContext(ctx,
+		"DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)", idle.Seconds())
+	return err
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"sync/atomic"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads makes reads done with ctx go to the primary, for callers
+// that must see their own writes before replicas catch up.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+func primaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// replicaSet spreads reads over replicas round-robin, falling back to the primary.
+type replicaSet struct {
+	primary  *sqlx.DB
+	replicas []*sqlx.DB
+	next     atomic.Uint64
+}
+
+func (r *replicaSet) reader(ctx context.Context) *sqlx.DB {
+	if len(r.replicas) == 0 || primaryReads(ctx) {
+		return r.primary
+	}
+	return r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReplicaSet_Reader(t *testing.T) {
+
+	primary := sqlx.NewDb(nil, "postgres")
+	first, second := sqlx.NewDb(nil, "postgres"), sqlx.NewDb(nil, "postgres")
+	ctx := context.Background()
+
+	assert.Same(t, primary, (&replicaSet{primary: primary}).reader(ctx))
+
+	reads := &replicaSet{primary: primary, replicas: []*sqlx.DB{first, second}}
+	seen := map[*sqlx.DB]bool{reads.reader(ctx): true, reads.reader(ctx): true}
+	assert.True(t, seen[first] && seen[second])
+
+	assert.Same(t, primary, reads.reader(WithPrimaryReads(ctx)))
+}
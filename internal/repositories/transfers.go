@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"slices"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+	"test-task/internal/tracing"
+)
+
+// Transfer debits Amount in Currency from the source wallet and credits
+// ToAmount in ToCurrency to the destination, recording FxRate on both legs.
+// The debit is checked against the source wallet's withdrawal limits.
+func (repo *Wallets) Transfer(ctx context.Context, transfer entities.Transfer,
+	limits entities.CurrencyWithdrawalLimits) (_ entities.Transfer, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.Transfer", tracing.Transfer(transfer.FromWalletID, transfer.ToWalletID)...)
+	defer tracing.End(span, &err)
+
+	err = withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		if err := lockWallets(ctx, tx, transfer.FromWalletID, transfer.ToWalletID); err != nil {
+			return err
+		}
+
+		if err := checkWithdrawalLimits(ctx, tx, transfer.FromWalletID, transfer.Amount, limits); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowxContext(ctx,
+			`INSERT INTO transfers (from_wallet_id, to_wallet_id, amount, currency, to_amount, to_currency, fx_rate)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+			transfer.FromWalletID, transfer.ToWalletID, transfer.Amount, transfer.Currency,
+			transfer.ToAmount, transfer.ToCurrency, transfer.FxRate,
+		).Scan(&transfer.ID, &transfer.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		debit := entities.Transaction{
+			WalletID:      transfer.FromWalletID,
+			Currency:      transfer.Currency,
+			OperationType: entities.TransferOut,
+			TransferID:    &transfer.ID,
+			FxRate:        transfer.FxRate,
+		}
+		if _, err := applyDelta(ctx, tx, debit, transfer.Amount.Neg()); err != nil {
+			return err
+		}
+
+		credit := entities.Transaction{
+			WalletID:      transfer.ToWalletID,
+			Currency:      transfer.ToCurrency,
+			OperationType: entities.TransferIn,
+			TransferID:    &transfer.ID,
+			FxRate:        transfer.FxRate,
+		}
+		_, err = applyDelta(ctx, tx, credit, transfer.ToAmount)
+		return err
+	})
+
+	return transfer, err
+}
+
+// lockWallets takes row locks in id order, so concurrent transfers
+// in opposite directions between the same wallets cannot deadlock.
+func lockWallets(ctx context.Context, tx *sqlx.Tx, ids ...string) error {
+
+	var locked []string
+	err := tx.SelectContext(ctx, &locked,
+		"SELECT id FROM wallets WHERE id = ANY($1) ORDER BY id FOR UPDATE", pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if !slices.Contains(locked, id) {
+			return fmt.Errorf("%w: wallet by id %s", errs.NotFound, id)
+		}
+	}
+
+	return nil
+}
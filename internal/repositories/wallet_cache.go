@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"sync"
+	"test-task/internal/entities"
+	"test-task/internal/logging"
+	"time"
+)
+
+const walletChangedChannel = "wallet_changed"
+
+// allWalletsChanged is the notification payload for writes touching wallets not known by ID.
+const allWalletsChanged = "*"
+
+type cachedWallet struct {
+	wallet    entities.Wallet
+	expiresAt time.Time
+}
+
+// pendingFill tracks the reads in flight for a wallet, generation is bumped by
+// invalidations racing with them.
+type pendingFill struct {
+	readers    int
+	generation uint64
+}
+
+// CachedWallets serves GetById from memory for up to ttl. Entries are dropped
+// locally after every write made through it, and on notifications the writing
+// instance sends for the others; ttl bounds the staleness should a
+// notification be missed or a wallet be changed outside the service. Misses
+// are filled from the primary, as a lagging replica's row would outlive the
+// invalidation sent for the write it has not replayed yet. A ttl of zero
+// disables caching, and with it the notifications.
+type CachedWallets struct {
+	*Wallets
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]cachedWallet
+	fills   map[string]*pendingFill
+}
+
+func NewCachedWalletsRepository(wallets *Wallets, ttl time.Duration) *CachedWallets {
+	return &CachedWallets{Wallets: wallets, ttl: ttl, entries: make(map[string]cachedWallet),
+		fills: make(map[string]*pendingFill)}
+}
+
+func (c *CachedWallets) GetById(ctx context.Context, id string) (entities.Wallet, error) {
+
+	if c.ttl <= 0 || primaryReads(ctx) {
+		return c.Wallets.GetById(ctx, id)
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.wallet, nil
+	}
+
+	c.mu.Lock()
+	fill, ok := c.fills[id]
+	if !ok {
+		fill = &pendingFill{}
+		c.fills[id] = fill
+	}
+	fill.readers++
+	generation := fill.generation
+	c.mu.Unlock()
+
+	wallet, err := c.Wallets.GetById(WithPrimaryReads(ctx), id)
+
+	// an invalidation of the wallet while reading may mean the row read is already stale
+	c.mu.Lock()
+	if err == nil && fill.generation == generation {
+		c.entries[id] = cachedWallet{wallet: wallet, expiresAt: time.Now().Add(c.ttl)}
+	}
+	if fill.readers--; fill.readers == 0 {
+		delete(c.fills, id)
+	}
+	c.mu.Unlock()
+
+	return wallet, err
+}
+
+func (c *CachedWallets) Invalidate(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		delete(c.entries, id)
+		if fill, ok := c.fills[id]; ok {
+			fill.generation++
+		}
+	}
+}
+
+func (c *CachedWallets) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cachedWallet)
+	for _, fill := range c.fills {
+		fill.generation++
+	}
+}
+
+// changed invalidates the wallets written and, after a successful write, notifies
+// the other instances. Only caching instances pay for it, a failed notification
+// leaves their entries to expire.
+func (c *CachedWallets) changed(ctx context.Context, err error, ids ...string) {
+
+	if c.ttl <= 0 || len(ids) == 0 {
+		return
+	}
+
+	if ids[0] == allWalletsChanged {
+		c.InvalidateAll()
+	} else {
+		c.Invalidate(ids...)
+	}
+
+	if err != nil {
+		return
+	}
+
+	_, err = c.db.ExecContext(ctx, "SELECT pg_notify($1, id) FROM unnest($2::text[]) AS id",
+		walletChangedChannel, pq.StringArray(ids))
+	if err != nil {
+		logging.FromContext(ctx).Errorf("error notify wallet changes: %v", err)
+	}
+}
+
+// Listen invalidates entries on wallet change notifications until ctx is done,
+// and drops the whole cache on a change to unknown wallets or whenever the
+// connection was lost, as notifications sent meanwhile are gone.
+func (c *CachedWallets) Listen(ctx context.Context, connectionString string) error {
+
+	if c.ttl <= 0 {
+		return nil
+	}
+
+	listener := pq.NewListener(connectionString, time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logging.FromContext(ctx).Errorf("wallet cache listener: %v", err)
+			}
+		})
+	defer listener.Close()
+
+	if err := listener.Listen(walletChangedChannel); err != nil {
+		return err
+	}
+
+	sweep := time.NewTicker(c.ttl)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			if notification == nil || notification.Extra == allWalletsChanged {
+				c.InvalidateAll()
+				continue
+			}
+			c.Invalidate(notification.Extra)
+		case <-sweep.C:
+			c.evictExpired()
+		}
+	}
+}
+
+func (c *CachedWallets) evictExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+}
+
+func (c *CachedWallets) ChangeBalance(ctx context.Context, change entities.BalanceChange) (entities.Transaction, error) {
+	transaction, err := c.Wallets.ChangeBalance(ctx, change)
+	c.changed(ctx, err, change.WalletID)
+	return transaction, err
+}
+
+func (c *CachedWallets) ChangeBalances(ctx context.Context,
+	changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error) {
+
+	ids := make([]string, len(changes))
+	for i, change := range changes {
+		ids[i] = change.WalletID
+	}
+
+	results, err := c.Wallets.ChangeBalances(ctx, changes)
+	c.changed(ctx, err, ids...)
+	return results, err
+}
+
+func (c *CachedWallets) Transfer(ctx context.Context, transfer entities.Transfer,
+	limits entities.CurrencyWithdrawalLimits) (entities.Transfer, error) {
+	result, err := c.Wallets.Transfer(ctx, transfer, limits)
+	c.changed(ctx, err, transfer.FromWalletID, transfer.ToWalletID)
+	return result, err
+}
+
+func (c *CachedWallets) UpdateStatus(ctx context.Context, id string, status entities.WalletStatus,
+	from []entities.WalletStatus) (entities.Wallet, error) {
+	wallet, err := c.Wallets.UpdateStatus(ctx, id, status, from)
+	c.changed(ctx, err, id)
+	return wallet, err
+}
+
+func (c *CachedWallets) SetCreditLimit(ctx context.Context, id string, currency string,
+	creditLimit decimal.Decimal) (entities.Wallet, error) {
+	wallet, err := c.Wallets.SetCreditLimit(ctx, id, currency, creditLimit)
+	c.changed(ctx, err, id)
+	return wallet, err
+}
+
+func (c *CachedWallets) SetWithdrawalLimits(ctx context.Context, id string, currency string,
+	limits entities.WithdrawalLimitOverrides) (entities.Wallet, error) {
+	wallet, err := c.Wallets.SetWithdrawalLimits(ctx, id, currency, limits)
+	c.changed(ctx, err, id)
+	return wallet, err
+}
+
+func (c *CachedWallets) CreateHold(ctx context.Context, hold entities.Hold,
+	limits entities.CurrencyWithdrawalLimits) (entities.Hold, error) {
+	created, err := c.Wallets.CreateHold(ctx, hold, limits)
+	c.changed(ctx, err, hold.WalletID)
+	return created, err
+}
+
+func (c *CachedWallets) CaptureHold(ctx context.Context, id string, currency string,
+	amount *decimal.Decimal, limits entities.CurrencyWithdrawalLimits) (entities.Hold, error) {
+	hold, err := c.Wallets.CaptureHold(ctx, id, currency, amount, limits)
+	c.changed(ctx, err, hold.WalletID)
+	return hold, err
+}
+
+func (c *CachedWallets) VoidHold(ctx context.Context, id string) (entities.Hold, error) {
+	hold, err := c.Wallets.VoidHold(ctx, id)
+	c.changed(ctx, err, hold.WalletID)
+	return hold, err
+}
+
+func (c *CachedWallets) ExpireHolds(ctx context.Context, now time.Time) (int64, error) {
+	expired, err := c.Wallets.ExpireHolds(ctx, now)
+	if expired > 0 {
+		c.changed(ctx, err, allWalletsChanged)
+	}
+	return expired, err
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const walletRateLimitsWalletFkey = "wallet_rate_limits_wallet_id_fkey"
+
+func (repo *Wallets) SetRateLimits(ctx context.Context, limits entities.WalletRateLimits) (entities.WalletRateLimits, error) {
+
+	var stored entities.WalletRateLimits
+	err := repo.db.GetContext(ctx, &stored,
+		`INSERT INTO wallet_rate_limits (wallet_id, read_rate, read_burst, write_rate, write_burst)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id) DO UPDATE SET read_rate = EXCLUDED.read_rate, read_burst = EXCLUDED.read_burst,
+			write_rate = EXCLUDED.write_rate, write_burst = EXCLUDED.write_burst
+		RETURNING *`,
+		limits.WalletID, limits.ReadRate, limits.ReadBurst, limits.WriteRate, limits.WriteBurst)
+	if isForeignKeyViolation(err, walletRateLimitsWalletFkey) {
+		return stored, fmt.Errorf("%w: wallet by id %s", errs.NotFound, limits.WalletID)
+	}
+
+	return stored, err
+}
+
+func (repo *Wallets) ListRateLimits(ctx context.Context) ([]entities.WalletRateLimits, error) {
+
+	limits := []entities.WalletRateLimits{}
+	if err := repo.db.SelectContext(ctx, &limits, "SELECT * FROM wallet_rate_limits"); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
@@ -6,24 +6,38 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
-	"strings"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"test-task/internal/entities"
 	errs "test-task/internal/errors"
+	"test-task/internal/logging"
+	"test-task/internal/tracing"
+	"time"
 )
 
-const balanceNotNegativeCheck = "check_balance_non_negative"
+const (
+	idempotencyKeysPkey    = "idempotency_keys_pkey"
+	closedBalanceZeroCheck = "check_closed_balance_zero"
+	closedNoHoldsCheck     = "check_closed_no_holds"
+)
 
 type Wallets struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	reads *replicaSet
 }
 
-func NewWalletsRepository(db *sqlx.DB) *Wallets {
-	return &Wallets{db: db}
+// NewWalletsRepository writes to db and serves wallet and history reads from replicas when given.
+func NewWalletsRepository(db *sqlx.DB, replicas ...*sqlx.DB) *Wallets {
+	return &Wallets{db: db, reads: &replicaSet{primary: db, replicas: replicas}}
 }
 
-func (repo *Wallets) GetById(ctx context.Context, id string) (entities.Wallet, error) {
+func (repo *Wallets) GetById(ctx context.Context, id string) (_ entities.Wallet, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.GetById", tracing.WalletID(id))
+	defer tracing.End(span, &err)
+
 	var wallet entities.Wallet
-	err := repo.db.GetContext(ctx, &wallet, "SELECT * FROM wallets WHERE id = $1", id)
+	err = repo.reads.reader(ctx).GetContext(ctx, &wallet, "SELECT * FROM wallets WHERE id = $1", id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return wallet, fmt.Errorf("%w: wallet by id %s", errs.NotFound, id)
@@ -34,23 +48,327 @@ func (repo *Wallets) GetById(ctx context.Context, id string) (entities.Wallet, e
 	return wallet, nil
 }
 
-func (repo *Wallets) ChangeBalance(ctx context.Context, id string, delta float64) error {
+func (repo *Wallets) ChangeBalance(ctx context.Context, change entities.BalanceChange) (entities.Transaction, error) {
 
-	res, err := repo.db.ExecContext(ctx, "UPDATE wallets SET balance = balance + $1 where id = $2", delta, id)
+	results, err := repo.ChangeBalances(ctx, []entities.BalanceChange{change})
 	if err != nil {
-		if strings.Contains(err.Error(), balanceNotNegativeCheck) {
-			return errs.InsufficientBalance
+		return entities.Transaction{}, err
+	}
+
+	return results[0].Transaction, results[0].Err
+}
+
+// ChangeBalances applies changes in order within one transaction. Each change
+// runs under its own savepoint, so a failed one is rolled back and reported
+// in its result without affecting the others.
+func (repo *Wallets) ChangeBalances(ctx context.Context,
+	changes []entities.BalanceChange) (_ []entities.BalanceChangeResult, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.ChangeBalances", tracing.BatchSize(len(changes)))
+	defer tracing.End(span, &err)
+	if len(changes) > 0 {
+		tracing.Annotate(ctx, tracing.WalletID(changes[0].WalletID), tracing.Operation(changes[0].OperationType))
+	}
+
+	results := make([]entities.BalanceChangeResult, len(changes))
+
+	err = withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		for i, change := range changes {
+
+			changeCtx := ctx
+			if change.RequestID != "" {
+				changeCtx = logging.WithRequestID(ctx, change.RequestID)
+			}
+
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT balance_change"); err != nil {
+				return err
+			}
+
+			transaction, err := changeBalance(changeCtx, tx, change)
+			if err == nil {
+				_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT balance_change")
+				if err != nil {
+					return err
+				}
+				results[i] = entities.BalanceChangeResult{Transaction: transaction}
+				continue
+			}
+
+			if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT balance_change"); rollbackErr != nil {
+				return rollbackErr
+			}
+
+			if change.IdempotencyKey != nil && isUniqueViolation(err, idempotencyKeysPkey) {
+				logging.FromContext(changeCtx).Debugf("replaying idempotency key %s", change.IdempotencyKey.Key)
+				transaction, err = getIdempotentTransaction(ctx, tx, change.WalletID, *change.IdempotencyKey)
+			}
+			results[i] = entities.BalanceChangeResult{Transaction: transaction, Err: err}
 		}
-		return err
+
+		return nil
+	})
+
+	return results, err
+}
+
+func changeBalance(ctx context.Context, tx *sqlx.Tx, change entities.BalanceChange) (entities.Transaction, error) {
+
+	if change.IdempotencyKey != nil {
+		transaction, replayed, err := replayIdempotencyKey(ctx, tx, change.WalletID, *change.IdempotencyKey)
+		if replayed || err != nil {
+			return transaction, err
+		}
+	}
+
+	if change.Delta.IsNegative() {
+		if err := checkWithdrawalLimits(ctx, tx, change.WalletID, change.Delta.Abs(), change.Limits); err != nil {
+			return entities.Transaction{}, err
+		}
+	}
+
+	transaction, err := applyDelta(ctx, tx, entities.Transaction{
+		WalletID:      change.WalletID,
+		Currency:      change.Currency,
+		OperationType: change.OperationType,
+	}, change.Delta)
+	if err != nil || change.IdempotencyKey == nil {
+		return transaction, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO idempotency_keys (wallet_id, key, request_hash, transaction_id) VALUES ($1, $2, $3, $4)",
+		change.WalletID, change.IdempotencyKey.Key, change.IdempotencyKey.RequestHash, transaction.ID)
+	return transaction, err
+}
+
+// replayIdempotencyKey serializes requests sharing the key on the wallet and
+// returns the transaction already stored under it, so a replay never re-runs
+// the limit and balance checks against the state its own first attempt produced.
+// Keys are scoped to the wallet, other wallets may use the same key.
+func replayIdempotencyKey(ctx context.Context, tx *sqlx.Tx, walletID string,
+	idempotencyKey entities.IdempotencyKey) (entities.Transaction, bool, error) {
+
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1 || ':' || $2, 0))",
+		walletID, idempotencyKey.Key)
+	if err != nil {
+		return entities.Transaction{}, false, err
+	}
+
+	transaction, err := getIdempotentTransaction(ctx, tx, walletID, idempotencyKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Transaction{}, false, nil
+	}
+	if err == nil {
+		logging.FromContext(ctx).Debugf("replaying idempotency key %s", idempotencyKey.Key)
+	}
+	return transaction, true, err
+}
+
+func getIdempotentTransaction(ctx context.Context, tx *sqlx.Tx, walletID string,
+	idempotencyKey entities.IdempotencyKey) (entities.Transaction, error) {
+
+	var stored struct {
+		RequestHash string `db:"request_hash"`
+		entities.Transaction
+	}
+	err := tx.GetContext(ctx, &stored,
+		`SELECT k.request_hash, `+transactionColumns("t")+`
+		FROM idempotency_keys k JOIN wallet_transactions t ON t.id = k.transaction_id
+		WHERE k.wallet_id = $1 AND k.key = $2`, walletID, idempotencyKey.Key)
+	if err != nil {
+		return entities.Transaction{}, err
+	}
+
+	if stored.RequestHash != idempotencyKey.RequestHash {
+		return entities.Transaction{}, errs.IdempotencyConflict
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	return stored.Transaction, nil
+}
+
+// DeleteIdempotencyKeys forgets the keys stored before cutoff, their
+// requests can no longer be replayed.
+func (repo *Wallets) DeleteIdempotencyKeys(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := repo.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", cutoff)
 	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (repo *Wallets) GetTransactions(ctx context.Context,
+	filter entities.TransactionFilter) (_ []entities.Transaction, err error) {
+
+	ctx, span := tracing.Start(ctx, "Wallets.GetTransactions", tracing.WalletID(filter.WalletID))
+	defer tracing.End(span, &err)
+
+	q := newQueryBuilder("SELECT "+transactionColumns("")+" FROM wallet_transactions WHERE wallet_id = $1", filter.WalletID)
+
+	if filter.OperationType != "" {
+		q.where("operation_type = $%d", filter.OperationType)
+	}
+	if filter.MinAmount != nil {
+		q.where("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		q.where("amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		q.where("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		q.where("created_at < $%d", *filter.To)
+	}
+	if filter.After != nil {
+		q.where("seq < $%d", filter.After.Seq)
+	}
+	q.suffix("ORDER BY seq DESC LIMIT $%d", filter.Limit)
+
+	transactions := []entities.Transaction{}
+	if err := repo.reads.reader(ctx).SelectContext(ctx, &transactions, q.query, q.args...); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (repo *Wallets) Create(ctx context.Context, ownerID *string, ownerRef *string, currency string,
+	initialBalance decimal.Decimal) (entities.Wallet, error) {
+
+	var wallet entities.Wallet
+
+	err := withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		err := tx.GetContext(ctx, &wallet,
+			"INSERT INTO wallets (owner_id, owner_ref, currency) VALUES ($1, $2, $3) RETURNING *",
+			ownerID, ownerRef, currency)
+		if err != nil || initialBalance.IsZero() {
+			return err
+		}
+
+		transaction, err := applyDelta(ctx, tx,
+			entities.Transaction{WalletID: wallet.ID, Currency: currency, OperationType: entities.Deposit}, initialBalance)
+		wallet.Balance = transaction.BalanceAfter
 		return err
+	})
+
+	return wallet, err
+}
+
+func (repo *Wallets) List(ctx context.Context, filter entities.WalletFilter) ([]entities.Wallet, error) {
+
+	q := newQueryBuilder("SELECT * FROM wallets WHERE TRUE")
+
+	if filter.OwnerRef != "" {
+		q.where("owner_ref = $%d", filter.OwnerRef)
+	}
+	if filter.OwnerID != "" {
+		q.where("owner_id = $%d", filter.OwnerID)
+	}
+	if filter.Currency != "" {
+		q.where("currency = $%d", filter.Currency)
+	}
+	if filter.Status != "" {
+		q.where("status = $%d", filter.Status)
+	}
+	if filter.MinBalance != nil {
+		q.where("balance >= $%d", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		q.where("balance <= $%d", *filter.MaxBalance)
+	}
+	if filter.After != nil {
+		q.where("(created_at, id) < ($%d, $%d)", filter.After.CreatedAt, filter.After.ID)
+	}
+	q.suffix("ORDER BY created_at DESC, id DESC LIMIT $%d", filter.Limit)
+
+	wallets := []entities.Wallet{}
+	if err := repo.db.SelectContext(ctx, &wallets, q.query, q.args...); err != nil {
+		return nil, err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("%w: wallet by id %s", errs.NotFound, id)
+
+	return wallets, nil
+}
+
+func (repo *Wallets) UpdateStatus(ctx context.Context, id string, status entities.WalletStatus,
+	from []entities.WalletStatus) (entities.Wallet, error) {
+
+	allowed := make([]string, len(from))
+	for i, s := range from {
+		allowed[i] = string(s)
 	}
 
-	return nil
+	var wallet entities.Wallet
+	err := withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		if err := lockWallets(ctx, tx, id); err != nil {
+			return err
+		}
+
+		err := tx.GetContext(ctx, &wallet,
+			"UPDATE wallets SET status = $1 WHERE id = $2 AND status = ANY($3) RETURNING *",
+			status, id, pq.Array(allowed))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: wallet %s cannot become %s", errs.InvalidStatusTransition, id, status)
+			}
+			if isCheckViolation(err, closedBalanceZeroCheck) {
+				return fmt.Errorf("%w: wallet %s must have zero balance to be closed", errs.InvalidStatusTransition, id)
+			}
+			if isCheckViolation(err, closedNoHoldsCheck) {
+				return fmt.Errorf("%w: wallet %s has active holds", errs.InvalidStatusTransition, id)
+			}
+			return err
+		}
+
+		return nil
+	})
+
+	return wallet, err
+}
+
+func (repo *Wallets) SetCreditLimit(ctx context.Context, id string, currency string,
+	creditLimit decimal.Decimal) (entities.Wallet, error) {
+
+	var wallet entities.Wallet
+	err := withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		err := tx.GetContext(ctx, &wallet,
+			"UPDATE wallets SET credit_limit = $1 WHERE id = $2 AND currency = $3 RETURNING *",
+			creditLimit, id, currency)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return unavailableWalletError(ctx, tx, id, currency)
+			}
+			if isCreditLimitViolation(err) {
+				return fmt.Errorf("%w: wallet %s is overdrawn beyond %s", errs.InsufficientBalance, id, creditLimit)
+			}
+			return err
+		}
+
+		return nil
+	})
+
+	return wallet, err
+}
+
+func (repo *Wallets) SetWithdrawalLimits(ctx context.Context, id string, currency string,
+	limits entities.WithdrawalLimitOverrides) (entities.Wallet, error) {
+
+	var wallet entities.Wallet
+	err := withTx(ctx, repo.db, func(tx *sqlx.Tx) error {
+
+		err := tx.GetContext(ctx, &wallet,
+			`UPDATE wallets SET per_operation_withdrawal_limit = $1, daily_withdrawal_limit = $2,
+			monthly_withdrawal_limit = $3
+			WHERE id = $4 AND currency = $5 RETURNING *`,
+			limits.PerOperation, limits.Daily, limits.Monthly, id, currency)
+		if errors.Is(err, sql.ErrNoRows) {
+			return unavailableWalletError(ctx, tx, id, currency)
+		}
+		return err
+	})
+
+	return wallet, err
 }
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+	"time"
+)
+
+// checkWithdrawalLimits locks the wallet and rejects a debit of amount that
+// exceeds its limits, so concurrent debits are counted one after another.
+func checkWithdrawalLimits(ctx context.Context, tx *sqlx.Tx, walletID string, amount decimal.Decimal,
+	defaults entities.CurrencyWithdrawalLimits) error {
+
+	var wallet entities.Wallet
+	err := tx.GetContext(ctx, &wallet, "SELECT * FROM wallets WHERE id = $1 FOR UPDATE", walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: wallet by id %s", errs.NotFound, walletID)
+		}
+		return err
+	}
+
+	limits := wallet.WithdrawalLimits(defaults)
+
+	if limits.PerOperation.IsPositive() && amount.GreaterThan(limits.PerOperation) {
+		return &errs.LimitExceeded{Limit: entities.PerOperationLimit, Amount: limits.PerOperation}
+	}
+
+	windows := []struct {
+		name   string
+		limit  decimal.Decimal
+		length time.Duration
+	}{
+		{entities.DailyLimit, limits.Daily, entities.DailyLimitWindow},
+		{entities.MonthlyLimit, limits.Monthly, entities.MonthlyLimitWindow},
+	}
+
+	for _, window := range windows {
+		if !window.limit.IsPositive() {
+			continue
+		}
+
+		var spent decimal.Decimal
+		err := tx.GetContext(ctx, &spent,
+			`SELECT COALESCE(SUM(amount), 0) FROM wallet_transactions
+			WHERE wallet_id = $1 AND operation_type = ANY($2) AND created_at > now() - make_interval(secs => $3)`,
+			walletID, pq.Array(entities.Debits), window.length.Seconds())
+		if err != nil {
+			return err
+		}
+
+		if spent.Add(amount).LessThanOrEqual(window.limit) {
+			continue
+		}
+
+		limitErr := &errs.LimitExceeded{Limit: window.name, Amount: window.limit}
+		if amount.LessThanOrEqual(window.limit) {
+			resetAt, err := windowResetAt(ctx, tx, walletID, spent.Add(amount).Sub(window.limit), window.length)
+			if err != nil {
+				return err
+			}
+			limitErr.ResetAt = &resetAt
+		}
+		return limitErr
+	}
+
+	return nil
+}
+
+// windowResetAt finds when the oldest debits adding up to at least excess
+// leave the window.
+func windowResetAt(ctx context.Context, tx *sqlx.Tx, walletID string, excess decimal.Decimal,
+	length time.Duration) (time.Time, error) {
+
+	var resetAt time.Time
+	err := tx.GetContext(ctx, &resetAt,
+		`SELECT created_at + make_interval(secs => $3) FROM (
+			SELECT created_at, SUM(amount) OVER (ORDER BY created_at, id) AS released
+			FROM wallet_transactions
+			WHERE wallet_id = $1 AND operation_type = ANY($2) AND created_at > now() - make_interval(secs => $3)
+		) debits
+		WHERE released >= $4 ORDER BY created_at LIMIT 1`,
+		walletID, pq.Array(entities.Debits), length.Seconds(), excess)
+
+	return resetAt, err
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"test-task/internal/repositories"
+	"time"
+)
+
+const consistencyTokenHeader = "X-Consistency-Token"
+
+// consistencyTokenSkew is how far ahead of this instance's clock a token
+// issued by another instance may be.
+const consistencyTokenSkew = time.Second
+
+// readYourWrites hands out a token with every write and sends the reads of
+// a client presenting a token younger than maxLag to the primary, as replicas
+// may not have caught up with its write yet.
+func readYourWrites(maxLag time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		if fresh(ctx.GetHeader(consistencyTokenHeader), maxLag, time.Now()) {
+			ctx.Request = ctx.Request.WithContext(repositories.WithPrimaryReads(ctx.Request.Context()))
+		}
+
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			ctx.Header(consistencyTokenHeader, strconv.FormatInt(time.Now().UnixMilli(), 10))
+		}
+
+		ctx.Next()
+	}
+}
+
+// fresh reports whether token was issued less than maxLag before now. Tokens
+// from the future are rejected, so a client cannot pin its reads to the
+// primary by sending a far-off timestamp.
+func fresh(token string, maxLag time.Duration, now time.Time) bool {
+
+	writtenAt, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	age := now.Sub(time.UnixMilli(writtenAt))
+	return age > -consistencyTokenSkew && age < maxLag
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFresh_ShouldOnlyAcceptRecentPastTokens(t *testing.T) {
+
+	now := time.Now()
+	token := func(offset time.Duration) string {
+		return strconv.FormatInt(now.Add(offset).UnixMilli(), 10)
+	}
+
+	assert.True(t, fresh(token(-time.Second), 5*time.Second, now))
+	assert.True(t, fresh(token(500*time.Millisecond), 5*time.Second, now), "small clock skew is tolerated")
+	assert.False(t, fresh(token(-10*time.Second), 5*time.Second, now))
+	assert.False(t, fresh(token(time.Hour), 5*time.Second, now))
+	assert.False(t, fresh(strconv.FormatInt(1<<62, 10), 5*time.Second, now))
+	assert.False(t, fresh("", 5*time.Second, now))
+	assert.False(t, fresh("yesterday", 5*time.Second, now))
+}
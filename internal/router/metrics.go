@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"test-task/internal/metrics"
+	"time"
+)
+
+// requestMetrics records the latency of every request under its route
+// pattern, unmatched paths share one label so scans can't blow up cardinality.
+func requestMetrics(ctx *gin.Context) {
+
+	start := time.Now()
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.ObserveRequest(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status()), time.Since(start))
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"test-task/internal/logging"
+	"time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestID propagates the caller's X-Request-ID, or assigns one, through
+// the request context and back in the response.
+func requestID(ctx *gin.Context) {
+
+	id := ctx.GetHeader(requestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = uuid.NewString()
+	}
+
+	ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), id))
+	ctx.Header(requestIDHeader, id)
+
+	ctx.Next()
+}
+
+func accessLog(ctx *gin.Context) {
+
+	start := time.Now()
+	ctx.Next()
+
+	fields := log.Fields{
+		"method":     ctx.Request.Method,
+		"path":       ctx.Request.URL.Path,
+		"status":     ctx.Writer.Status(),
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if walletID := ctx.GetString(logging.WalletIDKey); walletID != "" {
+		fields["wallet_id"] = walletID
+	}
+	logging.FromContext(ctx).WithFields(fields).Info("request")
+}
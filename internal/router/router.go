@@ -3,20 +3,91 @@ package router
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"math"
 	"net/http"
+	"strconv"
 	"test-task/internal/dto"
+	"test-task/internal/entities"
 	errs "test-task/internal/errors"
 	"test-task/internal/handlers"
+	"test-task/internal/logging"
+	"time"
 )
 
-func Setup(engine *gin.Engine, walletHandler *handlers.WalletHandler) {
+const withdrawalLimitExceededCode = "withdrawal_limit_exceeded"
 
+type Options struct {
+	// ReplicaMaxLag is how long after a write its client keeps reading from the primary.
+	ReplicaMaxLag time.Duration
+	// ServiceName names the server in request spans.
+	ServiceName string
+	// RequireAuthentication guards the wallet API with bearer tokens or scoped
+	// API keys, and the admin API with admin tokens or admin API keys.
+	RequireAuthentication bool
+}
+
+func Setup(engine *gin.Engine, walletHandler *handlers.WalletHandler, adminHandler *handlers.AdminHandler,
+	healthHandler *handlers.HealthHandler, authHandler *handlers.AuthHandler, options Options) {
+
+	engine.ContextWithFallback = true
+
+	engine.Use(requestID)
+	engine.Use(accessLog)
 	engine.Use(gin.Recovery())
+	engine.Use(otelgin.Middleware(options.ServiceName, otelgin.WithFilter(isTraced)))
+	engine.Use(requestMetrics)
 	engine.Use(errorHandler)
+	if options.ReplicaMaxLag > 0 {
+		engine.Use(readYourWrites(options.ReplicaMaxLag))
+	}
+
+	engine.GET("/healthz", healthHandler.Live)
+	engine.GET("/readyz", healthHandler.Ready)
+	engine.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	api := engine.Group("/api/v1")
+	requireScope := func(string) gin.HandlerFunc { return func(*gin.Context) {} }
+	requireOperationScope := func(*gin.Context) {}
+	if options.RequireAuthentication {
+		api.Use(authHandler.Authenticate)
+		requireScope = authHandler.RequireScope
+		requireOperationScope = authHandler.RequireOperationScope
+	}
+
+	api.POST("/wallets", requireScope(entities.ScopeDeposit), walletHandler.CreateWallet)
+	api.GET("/wallets", requireScope(entities.ScopeRead), walletHandler.ListWallets)
+	api.GET("/wallets/:id", requireScope(entities.ScopeRead), walletHandler.GetBalance)
+	api.GET("/wallets/:id/transactions", requireScope(entities.ScopeRead), walletHandler.GetTransactions)
+	api.POST("/wallet", requireOperationScope, walletHandler.RunOperation)
+	api.POST("/transfers", requireScope(entities.ScopeWithdraw), walletHandler.Transfer)
+	api.POST("/holds", requireScope(entities.ScopeWithdraw), walletHandler.CreateHold)
+	api.GET("/holds/:id", requireScope(entities.ScopeRead), walletHandler.GetHold)
+	api.POST("/holds/:id/capture", requireScope(entities.ScopeWithdraw), walletHandler.CaptureHold)
+	api.POST("/holds/:id/void", requireScope(entities.ScopeWithdraw), walletHandler.VoidHold)
 
-	engine.GET("/api/v1/wallets/:id", walletHandler.GetBalance)
-	engine.POST("/api/v1/wallet", walletHandler.RunOperation)
+	admin := engine.Group("/api/v1/admin")
+	if options.RequireAuthentication {
+		admin.Use(authHandler.Authenticate, authHandler.RequireAdmin)
+	}
+	admin.PUT("/wallets/:id/status", adminHandler.ChangeWalletStatus)
+	admin.PUT("/wallets/:id/credit-limit", adminHandler.SetCreditLimit)
+	admin.PUT("/wallets/:id/withdrawal-limits", adminHandler.SetWithdrawalLimits)
+	admin.PUT("/wallets/:id/rate-limits", adminHandler.SetRateLimits)
+	admin.GET("/fx-rates", adminHandler.ListFxRates)
+	admin.PUT("/fx-rates", adminHandler.UpsertFxRate)
+	admin.POST("/api-keys", adminHandler.IssueApiKey)
+	admin.POST("/api-keys/:id/revoke", adminHandler.RevokeApiKey)
+}
+
+// isTraced leaves probes and scrapes out of traces.
+func isTraced(request *http.Request) bool {
+	switch request.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
 }
 
 func errorHandler(ctx *gin.Context) {
@@ -26,14 +97,36 @@ func errorHandler(ctx *gin.Context) {
 	if len(ctx.Errors) > 0 {
 		err := ctx.Errors.Last()
 
-		if errors.Is(err, errs.NotFound) {
+		var limitErr *errs.LimitExceeded
+		if errors.As(err, &limitErr) {
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, dto.LimitExceededResponse{
+				Error:   limitErr.Error(),
+				Code:    withdrawalLimitExceededCode,
+				Limit:   limitErr.Limit,
+				ResetAt: limitErr.ResetAt,
+			})
+		} else if errors.Is(err, errs.Unauthorized) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, errs.Forbidden) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, errs.NotFound) {
 			ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		} else if errors.Is(err, errs.UnsupportedOperation) {
 			ctx.AbortWithStatusJSON(http.StatusNotImplemented, dto.ErrorResponse{Error: err.Error()})
 			logError(ctx, err)
-		} else if errors.Is(err, errs.InsufficientBalance) {
+		} else if errors.Is(err, errs.InsufficientBalance) || errors.Is(err, errs.CurrencyMismatch) ||
+			errors.Is(err, errs.AmountTooSmall) {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, errs.WalletUnavailable) || errors.Is(err, errs.InvalidStatusTransition) ||
+			errors.Is(err, errs.HoldNotActive) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, errs.IdempotencyConflict) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
 		} else if errors.Is(err, errs.TooManyRequests) {
+			var rateErr *errs.RateLimited
+			if errors.As(err, &rateErr) {
+				setRateLimitHeaders(ctx, rateErr)
+			}
 			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, dto.ErrorResponse{Error: err.Error()})
 		} else {
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "Internal server error"})
@@ -42,6 +135,18 @@ func errorHandler(ctx *gin.Context) {
 	}
 }
 
+func setRateLimitHeaders(ctx *gin.Context, err *errs.RateLimited) {
+	retryAfter := int(math.Ceil(err.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(err.Limit))
+	ctx.Header("X-RateLimit-Remaining", "0")
+	ctx.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(err.RetryAfter).Unix(), 10))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+}
+
 func logError(ctx *gin.Context, err error) {
-	log.Errorf("%s %s error: %s", ctx.Request.Method, ctx.Request.URL.Path, err)
+	logging.FromContext(ctx).Errorf("%s %s error: %s", ctx.Request.Method, ctx.Request.URL.Path, err)
 }
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+)
+
+const (
+	apiKeyPrefix        = "wk_"
+	apiKeyBytes         = 32
+	maxApiKeyNameLength = 255
+	// maxApiKeyOwnerLength matches wallets.owner_id.
+	maxApiKeyOwnerLength = 255
+)
+
+type apiKeysRepository interface {
+	Create(ctx context.Context, key entities.ApiKey) (entities.ApiKey, error)
+	GetActiveByHash(ctx context.Context, hash string) (entities.ApiKey, error)
+	Revoke(ctx context.Context, id string) (entities.ApiKey, error)
+}
+
+const bootstrapApiKeyName = "bootstrap"
+
+type ApiKeysService struct {
+	keys             apiKeysRepository
+	bootstrapKeyHash string
+}
+
+// NewApiKeysService accepts bootstrapKey, when set, as an admin key with
+// every scope, so the first keys can be issued before any exist.
+func NewApiKeysService(keys apiKeysRepository, bootstrapKey string) *ApiKeysService {
+	service := &ApiKeysService{keys: keys}
+	if bootstrapKey != "" {
+		service.bootstrapKeyHash = hashApiKey(bootstrapKey)
+	}
+	return service
+}
+
+type ApiKeyIssue struct {
+	name    string
+	scopes  []string
+	ownerID *string
+	admin   bool
+}
+
+// NewApiKeyIssue requires an owner for keys that are not admin keys, a key
+// can only act on the wallets of its owner.
+func NewApiKeyIssue(name string, scopes []string, ownerID *string, admin bool) (*ApiKeyIssue, error) {
+
+	if name == "" {
+		return nil, fmt.Errorf("name is empty")
+	}
+
+	if len(name) > maxApiKeyNameLength {
+		return nil, fmt.Errorf("name must be at most %d characters", maxApiKeyNameLength)
+	}
+
+	if ownerID != nil && (*ownerID == "" || len(*ownerID) > maxApiKeyOwnerLength) {
+		return nil, fmt.Errorf("owner ID must be 1 to %d characters", maxApiKeyOwnerLength)
+	}
+
+	if ownerID == nil && !admin {
+		return nil, fmt.Errorf("owner ID is required for non-admin keys")
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(entities.Scopes, scope) {
+			return nil, fmt.Errorf("invalid scope: %s, expected one of %v", scope, entities.Scopes)
+		}
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+
+	return &ApiKeyIssue{name: name, scopes: unique, ownerID: ownerID, admin: admin}, nil
+}
+
+// Issue creates a key and returns it alongside its record, it can't be recovered later.
+func (s *ApiKeysService) Issue(ctx context.Context, issue ApiKeyIssue) (entities.ApiKey, string, error) {
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return entities.ApiKey{}, "", err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	created, err := s.keys.Create(ctx, entities.ApiKey{
+		Name: issue.name, KeyHash: hashApiKey(key), Scopes: issue.scopes, OwnerID: issue.ownerID, Admin: issue.admin,
+	})
+	if err != nil {
+		return entities.ApiKey{}, "", err
+	}
+
+	return created, key, nil
+}
+
+func (s *ApiKeysService) Revoke(ctx context.Context, id string) (entities.ApiKey, error) {
+	return s.keys.Revoke(ctx, id)
+}
+
+func (s *ApiKeysService) Authenticate(ctx context.Context, key string) (entities.ApiKey, error) {
+
+	hash := hashApiKey(key)
+	if s.bootstrapKeyHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.bootstrapKeyHash)) == 1 {
+		return entities.ApiKey{Name: bootstrapApiKeyName, KeyHash: hash, Scopes: entities.Scopes, Admin: true}, nil
+	}
+
+	apiKey, err := s.keys.GetActiveByHash(ctx, hash)
+	if errors.Is(err, errs.NotFound) {
+		return entities.ApiKey{}, fmt.Errorf("%w: invalid api key", errs.Unauthorized)
+	}
+
+	return apiKey, err
+}
+
+// hashApiKey needs no salt or stretching, keys are random and long enough
+// that a plain digest can't be brute forced.
+func hashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+	"testing"
+)
+
+type stubApiKeys struct {
+	byHash map[string]entities.ApiKey
+}
+
+func (s stubApiKeys) Create(_ context.Context, key entities.ApiKey) (entities.ApiKey, error) {
+	s.byHash[key.KeyHash] = key
+	return key, nil
+}
+
+func (s stubApiKeys) GetActiveByHash(_ context.Context, hash string) (entities.ApiKey, error) {
+	if key, ok := s.byHash[hash]; ok {
+		return key, nil
+	}
+	return entities.ApiKey{}, fmt.Errorf("%w: api key", errs.NotFound)
+}
+
+func (s stubApiKeys) Revoke(_ context.Context, id string) (entities.ApiKey, error) {
+	return entities.ApiKey{}, fmt.Errorf("%w: api key by id %s", errs.NotFound, id)
+}
+
+func TestNewApiKeyIssue_ShouldValidateScopes(t *testing.T) {
+
+	merchant := "merchant"
+
+	issue, err := NewApiKeyIssue("payments", []string{"read", "withdraw", "read"}, &merchant, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"read", "withdraw"}, issue.scopes)
+
+	_, err = NewApiKeyIssue("payments", []string{"admin"}, nil, true)
+	assert.Error(t, err)
+
+	_, err = NewApiKeyIssue("payments", nil, &merchant, false)
+	assert.Error(t, err)
+
+	_, err = NewApiKeyIssue("", []string{"read"}, &merchant, false)
+	assert.Error(t, err)
+}
+
+func TestNewApiKeyIssue_ShouldRequireOwnerUnlessAdmin(t *testing.T) {
+
+	_, err := NewApiKeyIssue("payments", []string{"read"}, nil, false)
+	assert.Error(t, err)
+
+	empty := ""
+	_, err = NewApiKeyIssue("payments", []string{"read"}, &empty, false)
+	assert.Error(t, err)
+
+	issue, err := NewApiKeyIssue("ops", []string{"read"}, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, issue.admin)
+	assert.Nil(t, issue.ownerID)
+}
+
+func TestApiKeysService_Authenticate_ShouldAcceptBootstrapKeyAsAdmin(t *testing.T) {
+
+	service := NewApiKeysService(stubApiKeys{byHash: map[string]entities.ApiKey{}}, "bootstrap-admin-key")
+	ctx := context.Background()
+	merchant := "merchant"
+
+	bootstrap, err := service.Authenticate(ctx, "bootstrap-admin-key")
+	assert.NoError(t, err)
+	assert.True(t, bootstrap.Admin)
+	assert.Equal(t, entities.Scopes, []string(bootstrap.Scopes))
+
+	issue, err := NewApiKeyIssue("reader", []string{"read"}, &merchant, false)
+	assert.NoError(t, err)
+	_, key, err := service.Issue(ctx, *issue)
+	assert.NoError(t, err)
+
+	issued, err := service.Authenticate(ctx, key)
+	assert.NoError(t, err)
+	assert.False(t, issued.Admin)
+
+	_, err = service.Authenticate(ctx, "wk_unknown")
+	assert.ErrorIs(t, err, errs.Unauthorized)
+
+	_, err = NewApiKeysService(stubApiKeys{byHash: map[string]entities.ApiKey{}}, "").Authenticate(ctx, "")
+	assert.ErrorIs(t, err, errs.Unauthorized)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"test-task/internal/entities"
+	"test-task/internal/logging"
+	"test-task/internal/tracing"
+	"time"
+)
+
+type balanceChangesApplier func(ctx context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error)
+
+type pendingChange struct {
+	ctx    context.Context
+	change entities.BalanceChange
+	result chan entities.BalanceChangeResult
+}
+
+type changeBatch struct {
+	changes []pendingChange
+	timer   *time.Timer
+}
+
+// coalescer groups the balance changes of a wallet arriving within window
+// into one apply call, so a hot wallet takes its row lock once per batch
+// instead of once per operation. Changes are applied in arrival order and
+// every caller gets its own result.
+//
+// A batch runs under its own context rather than any caller's, so one caller
+// going away or carrying request-scoped values cannot affect the others. Each
+// change keeps its caller's request ID and the batch span links to every
+// caller's span.
+type coalescer struct {
+	window   time.Duration
+	maxBatch int
+	apply    balanceChangesApplier
+	mu       sync.Mutex
+	pending  map[string]*changeBatch
+}
+
+func newCoalescer(window time.Duration, maxBatch int, apply balanceChangesApplier) *coalescer {
+	return &coalescer{
+		window:   window,
+		maxBatch: maxBatch,
+		apply:    apply,
+		pending:  make(map[string]*changeBatch),
+	}
+}
+
+func (c *coalescer) submit(ctx context.Context, change entities.BalanceChange) (entities.Transaction, error) {
+
+	pending := pendingChange{ctx: ctx, change: change, result: make(chan entities.BalanceChangeResult, 1)}
+
+	c.mu.Lock()
+	batch, ok := c.pending[change.WalletID]
+	if !ok {
+		batch = &changeBatch{}
+		c.pending[change.WalletID] = batch
+		batch.timer = time.AfterFunc(c.window, func() { c.flush(change.WalletID, batch) })
+	}
+	batch.changes = append(batch.changes, pending)
+	full := len(batch.changes) >= c.maxBatch
+	c.mu.Unlock()
+
+	if full {
+		c.flush(change.WalletID, batch)
+	}
+
+	// a caller that stops waiting once its batch is applying may still see
+	// the change committed, which an idempotency key makes safe to retry
+	select {
+	case result := <-pending.result:
+		return result.Transaction, result.Err
+	case <-ctx.Done():
+		return entities.Transaction{}, ctx.Err()
+	}
+}
+
+func (c *coalescer) flush(walletID string, batch *changeBatch) {
+
+	c.mu.Lock()
+	if c.pending[walletID] != batch {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pending, walletID)
+	batch.timer.Stop()
+	c.mu.Unlock()
+
+	// callers that gave up while queued are dropped rather than applied behind their back
+	live := make([]pendingChange, 0, len(batch.changes))
+	for _, pending := range batch.changes {
+		if err := pending.ctx.Err(); err != nil {
+			pending.result <- entities.BalanceChangeResult{Err: err}
+			continue
+		}
+		live = append(live, pending)
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	changes := make([]entities.BalanceChange, len(live))
+	origins := make([]context.Context, len(live))
+	for i, pending := range live {
+		changes[i] = pending.change
+		changes[i].RequestID = logging.RequestID(pending.ctx)
+		origins[i] = pending.ctx
+	}
+
+	ctx, span := tracing.StartLinked("coalescer.flush", origins, tracing.WalletID(walletID), tracing.BatchSize(len(live)))
+	results, err := c.apply(ctx, changes)
+	tracing.End(span, &err)
+	for i, pending := range live {
+		if err != nil {
+			pending.result <- entities.BalanceChangeResult{Err: err}
+			continue
+		}
+		pending.result <- results[i]
+	}
+}
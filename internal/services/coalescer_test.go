@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"test-task/internal/entities"
+	"test-task/internal/logging"
+	"testing"
+	"time"
+)
+
+func TestCoalescer_ShouldBatchConcurrentChangesWithIndividualResults(t *testing.T) {
+
+	var mu sync.Mutex
+	var batches [][]entities.BalanceChange
+
+	c := newCoalescer(50*time.Millisecond, 100,
+		func(_ context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error) {
+			mu.Lock()
+			batches = append(batches, changes)
+			mu.Unlock()
+
+			results := make([]entities.BalanceChangeResult, len(changes))
+			for i, change := range changes {
+				if change.Delta.IsNegative() {
+					results[i].Err = fmt.Errorf("rejected")
+					continue
+				}
+				results[i].Transaction = entities.Transaction{Amount: change.Delta}
+			}
+			return results, nil
+		})
+
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(delta int64) {
+			defer wg.Done()
+			if delta%2 == 0 {
+				delta = -delta
+			}
+			transaction, err := c.submit(context.Background(),
+				entities.BalanceChange{WalletID: "w", Delta: decimal.NewFromInt(delta)})
+			if delta < 0 {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, transaction.Amount.Equal(decimal.NewFromInt(delta)))
+		}(int64(i))
+	}
+	wg.Wait()
+
+	assert.Len(t, batches, 1)
+	assert.Len(t, batches[0], 10)
+}
+
+func TestCoalescer_WhenBatchFull_ShouldFlushWithoutWaiting(t *testing.T) {
+
+	c := newCoalescer(time.Hour, 1,
+		func(_ context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error) {
+			return make([]entities.BalanceChangeResult, len(changes)), nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = c.submit(context.Background(), entities.BalanceChange{WalletID: "w", Delta: decimal.NewFromInt(1)})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("full batch was not flushed")
+	}
+}
+
+func TestCoalescer_WhenCallerGivesUp_ShouldReturnWithoutWaitingForBatch(t *testing.T) {
+
+	c := newCoalescer(time.Hour, 100,
+		func(_ context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error) {
+			return make([]entities.BalanceChangeResult, len(changes)), nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.submit(ctx, entities.BalanceChange{WalletID: "w", Delta: decimal.NewFromInt(1)})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("submit kept waiting after its context was done")
+	}
+}
+
+func TestCoalescer_ShouldApplyUnderOwnContextKeepingRequestIDs(t *testing.T) {
+
+	type callerKey struct{}
+
+	var applied []entities.BalanceChange
+	var applyCtx context.Context
+
+	c := newCoalescer(time.Hour, 2,
+		func(ctx context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error) {
+			applyCtx, applied = ctx, changes
+			return make([]entities.BalanceChangeResult, len(changes)), nil
+		})
+
+	wg := sync.WaitGroup{}
+	for _, requestID := range []string{"first", "second"} {
+		wg.Add(1)
+		go func(requestID string) {
+			defer wg.Done()
+			ctx := context.WithValue(logging.WithRequestID(context.Background(), requestID), callerKey{}, requestID)
+			_, err := c.submit(ctx, entities.BalanceChange{WalletID: "w", Delta: decimal.NewFromInt(1)})
+			assert.NoError(t, err)
+		}(requestID)
+	}
+	wg.Wait()
+
+	assert.Nil(t, applyCtx.Value(callerKey{}))
+	assert.Empty(t, logging.RequestID(applyCtx))
+	if assert.Len(t, applied, 2) {
+		assert.ElementsMatch(t, []string{"first", "second"}, []string{applied[0].RequestID, applied[1].RequestID})
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+)
+
+const fxRateScale = 10
+
+type fxRatesRepository interface {
+	Get(ctx context.Context, base string, quote string) (entities.FxRate, error)
+	Upsert(ctx context.Context, rate entities.FxRate) (entities.FxRate, error)
+	List(ctx context.Context) ([]entities.FxRate, error)
+}
+
+type FxRatesService struct {
+	rates fxRatesRepository
+}
+
+func NewFxRatesService(rates fxRatesRepository) *FxRatesService {
+	return &FxRatesService{rates: rates}
+}
+
+func NewFxRate(base money.Currency, quote money.Currency, rate decimal.Decimal) (*entities.FxRate, error) {
+
+	if base == quote {
+		return nil, fmt.Errorf("base and quote currencies must differ")
+	}
+
+	if !rate.IsPositive() {
+		return nil, fmt.Errorf("rate must be greater than zero")
+	}
+
+	if !money.HasScale(rate, fxRateScale) {
+		return nil, fmt.Errorf("rate must have at most %d decimal places", fxRateScale)
+	}
+
+	return &entities.FxRate{BaseCurrency: base.Code, QuoteCurrency: quote.Code, Rate: rate}, nil
+}
+
+func (s *FxRatesService) Upsert(ctx context.Context, rate entities.FxRate) (entities.FxRate, error) {
+	return s.rates.Upsert(ctx, rate)
+}
+
+func (s *FxRatesService) List(ctx context.Context) ([]entities.FxRate, error) {
+	return s.rates.List(ctx)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"test-task/internal/entities"
+	"test-task/internal/logging"
+	"test-task/internal/money"
+	"test-task/internal/tracing"
+	"time"
+)
+
+type WalletHold struct {
+	walletID string
+	currency money.Currency
+	amount   decimal.Decimal
+	ttl      time.Duration
+}
+
+// NewWalletHold reserves amount on the wallet for ttl, zero ttl means the configured default.
+func NewWalletHold(walletID string, currency money.Currency, amount decimal.Decimal, ttl time.Duration) (*WalletHold, error) {
+
+	id, err := uuid.Parse(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("walletId is not uuid")
+	}
+
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("hold amount must be greater than zero")
+	}
+
+	if err := currency.Validate(amount); err != nil {
+		return nil, err
+	}
+
+	if ttl < 0 {
+		return nil, fmt.Errorf("hold ttl must not be negative")
+	}
+
+	return &WalletHold{walletID: id.String(), currency: currency, amount: amount, ttl: ttl}, nil
+}
+
+type HoldCapture struct {
+	currency string
+	amount   *decimal.Decimal
+}
+
+// NewHoldCapture captures the full hold when amount is nil.
+func NewHoldCapture(currency *money.Currency, amount *decimal.Decimal) (*HoldCapture, error) {
+
+	if amount == nil {
+		return &HoldCapture{}, nil
+	}
+
+	if currency == nil {
+		return nil, fmt.Errorf("currency is required with a capture amount")
+	}
+
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("capture amount must be greater than zero")
+	}
+
+	if err := currency.Validate(*amount); err != nil {
+		return nil, err
+	}
+
+	return &HoldCapture{currency: currency.Code, amount: amount}, nil
+}
+
+func (s *WalletsService) CreateHold(ctx context.Context, hold WalletHold) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "WalletsService.CreateHold", tracing.WalletID(hold.walletID))
+	defer tracing.End(span, &err)
+
+	if err := s.authorizeWallet(ctx, hold.walletID); err != nil {
+		return entities.Hold{}, err
+	}
+
+	if err := s.allowWalletOperation(ctx, hold.walletID, writeAccess); err != nil {
+		return entities.Hold{}, err
+	}
+
+	ttl := hold.ttl
+	if ttl == 0 {
+		ttl = s.options.HoldTTL
+	}
+
+	return s.wallets.CreateHold(ctx, entities.Hold{
+		WalletID:  hold.walletID,
+		Currency:  hold.currency.Code,
+		Amount:    hold.amount,
+		ExpiresAt: time.Now().Add(ttl),
+	}, s.options.WithdrawalLimits)
+}
+
+func (s *WalletsService) GetHold(ctx context.Context, id string) (entities.Hold, error) {
+	return s.allowHoldOperation(ctx, id, readAccess)
+}
+
+func (s *WalletsService) CaptureHold(ctx context.Context, id string, capture HoldCapture) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "WalletsService.CaptureHold", tracing.HoldID(id))
+	defer tracing.End(span, &err)
+
+	if _, err := s.allowHoldOperation(ctx, id, writeAccess); err != nil {
+		return entities.Hold{}, err
+	}
+
+	return s.wallets.CaptureHold(ctx, id, capture.currency, capture.amount, s.options.WithdrawalLimits)
+}
+
+func (s *WalletsService) VoidHold(ctx context.Context, id string) (_ entities.Hold, err error) {
+
+	ctx, span := tracing.Start(ctx, "WalletsService.VoidHold", tracing.HoldID(id))
+	defer tracing.End(span, &err)
+
+	if _, err := s.allowHoldOperation(ctx, id, writeAccess); err != nil {
+		return entities.Hold{}, err
+	}
+
+	return s.wallets.VoidHold(ctx, id)
+}
+
+// allowHoldOperation loads the hold for a caller allowed to act on its wallet
+// and takes a token from that wallet's bucket, as the other wallet operations do.
+func (s *WalletsService) allowHoldOperation(ctx context.Context, id string, kind access) (entities.Hold, error) {
+
+	hold, err := s.wallets.GetHold(ctx, id)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+
+	if err := s.authorizeWallet(ctx, hold.WalletID); err != nil {
+		return entities.Hold{}, err
+	}
+
+	if err := s.allowWalletOperation(ctx, hold.WalletID, kind); err != nil {
+		return entities.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (s *WalletsService) holdsExpiry(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.options.HoldExpiryInterval):
+			expired, err := s.wallets.ExpireHolds(ctx, time.Now())
+			if err != nil {
+				logging.FromContext(ctx).Errorf("error expire holds: %v", err)
+			} else if expired > 0 {
+				logging.FromContext(ctx).Infof("expired %d holds", expired)
+			}
+		}
+	}
+}
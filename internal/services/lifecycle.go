@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"test-task/internal/auth"
+	"test-task/internal/entities"
+	"test-task/internal/money"
+)
+
+const maxOwnerRefLength = 255
+
+// statusTransitions lists, per target status, the statuses a wallet may move from.
+var statusTransitions = map[entities.WalletStatus][]entities.WalletStatus{
+	entities.WalletActive: {entities.WalletFrozen},
+	entities.WalletFrozen: {entities.WalletActive},
+	entities.WalletClosed: {entities.WalletActive, entities.WalletFrozen},
+}
+
+func ParseWalletStatus(value string) (entities.WalletStatus, error) {
+
+	status := entities.WalletStatus(value)
+	if _, ok := statusTransitions[status]; !ok {
+		return "", fmt.Errorf("invalid wallet status, expected: %s, %s or %s",
+			entities.WalletActive, entities.WalletFrozen, entities.WalletClosed)
+	}
+
+	return status, nil
+}
+
+type WalletCreation struct {
+	ownerRef       *string
+	currency       money.Currency
+	initialBalance decimal.Decimal
+}
+
+func NewWalletCreation(ownerRef *string, currency money.Currency, initialBalance decimal.Decimal) (*WalletCreation, error) {
+
+	if ownerRef != nil && (*ownerRef == "" || len(*ownerRef) > maxOwnerRefLength) {
+		return nil, fmt.Errorf("ownerRef must be between 1 and %d characters", maxOwnerRefLength)
+	}
+
+	if initialBalance.IsNegative() {
+		return nil, fmt.Errorf("initial balance must not be negative")
+	}
+
+	if err := currency.Validate(initialBalance); err != nil {
+		return nil, err
+	}
+
+	return &WalletCreation{ownerRef: ownerRef, currency: currency, initialBalance: initialBalance}, nil
+}
+
+func (s *WalletsService) CreateWallet(ctx context.Context, creation WalletCreation) (entities.Wallet, error) {
+	return s.wallets.Create(ctx, ownerID(ctx), creation.ownerRef, creation.currency.Code, creation.initialBalance)
+}
+
+func (s *WalletsService) ListWallets(ctx context.Context, filter entities.WalletFilter) (entities.Page[entities.Wallet], error) {
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && !principal.Admin {
+		if principal.Subject == "" {
+			return entities.Page[entities.Wallet]{Items: []entities.Wallet{}}, nil
+		}
+		filter.OwnerID = principal.Subject
+	}
+
+	limit := pageLimit(filter.Limit)
+	filter.Limit = limit + 1
+
+	wallets, err := s.wallets.List(ctx, filter)
+	if err != nil {
+		return entities.Page[entities.Wallet]{}, err
+	}
+
+	return toPage(wallets, limit, func(w entities.Wallet) entities.Cursor {
+		return entities.Cursor{CreatedAt: w.CreatedAt, ID: w.ID}
+	}), nil
+}
+
+func (s *WalletsService) ChangeStatus(ctx context.Context, id string, status entities.WalletStatus) (entities.Wallet, error) {
+	return s.wallets.UpdateStatus(ctx, id, status, statusTransitions[status])
+}
+
+type WalletCreditLimit struct {
+	currency money.Currency
+	limit    decimal.Decimal
+}
+
+func NewWalletCreditLimit(currency money.Currency, limit decimal.Decimal) (*WalletCreditLimit, error) {
+
+	if limit.IsNegative() {
+		return nil, fmt.Errorf("credit limit must not be negative")
+	}
+
+	if err := currency.Validate(limit); err != nil {
+		return nil, err
+	}
+
+	return &WalletCreditLimit{currency: currency, limit: limit}, nil
+}
+
+func (s *WalletsService) SetCreditLimit(ctx context.Context, id string, creditLimit WalletCreditLimit) (entities.Wallet, error) {
+	return s.wallets.SetCreditLimit(ctx, id, creditLimit.currency.Code, creditLimit.limit)
+}
+
+type WalletWithdrawalLimits struct {
+	currency money.Currency
+	limits   entities.WithdrawalLimitOverrides
+}
+
+// NewWalletWithdrawalLimits sets the wallet's own limits, a nil limit falls
+// back to the configured default and zero removes the limit.
+func NewWalletWithdrawalLimits(currency money.Currency, limits entities.WithdrawalLimitOverrides) (*WalletWithdrawalLimits, error) {
+
+	names := []string{entities.PerOperationLimit, entities.DailyLimit, entities.MonthlyLimit}
+	for i, limit := range []*decimal.Decimal{limits.PerOperation, limits.Daily, limits.Monthly} {
+		if limit == nil {
+			continue
+		}
+		if limit.IsNegative() {
+			return nil, fmt.Errorf("%s withdrawal limit must not be negative", names[i])
+		}
+		if err := currency.Validate(*limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WalletWithdrawalLimits{currency: currency, limits: limits}, nil
+}
+
+func (s *WalletsService) SetWithdrawalLimits(ctx context.Context, id string,
+	limits WalletWithdrawalLimits) (entities.Wallet, error) {
+	return s.wallets.SetWithdrawalLimits(ctx, id, limits.currency.Code, limits.limits)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"test-task/internal/auth"
+	"test-task/internal/entities"
+	"test-task/internal/errors"
+)
+
+// checkOwner lets the request's principal act on wallet. Requests without
+// a principal come from a deployment with authentication off and pass.
+func checkOwner(ctx context.Context, wallet entities.Wallet) error {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && !principal.Owns(wallet) {
+		return fmt.Errorf("%w: wallet %s belongs to another owner", errors.Forbidden, wallet.ID)
+	}
+	return nil
+}
+
+// authorizeWallet is checkOwner for a wallet not loaded yet, skipping the
+// lookup when ownership doesn't matter.
+func (s *WalletsService) authorizeWallet(ctx context.Context, id string) error {
+
+	if principal, ok := auth.PrincipalFrom(ctx); !ok || principal.Admin {
+		return nil
+	}
+
+	wallet, err := s.wallets.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkOwner(ctx, wallet)
+}
+
+// ownerID is the owner recorded for wallets the request creates.
+func ownerID(ctx context.Context) *string {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Subject != "" {
+		return &principal.Subject
+	}
+	return nil
+}
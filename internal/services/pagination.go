@@ -0,0 +1,29 @@
+package services
+
+import "test-task/internal/entities"
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+func pageLimit(requested int) int {
+	if requested <= 0 {
+		return defaultPageLimit
+	}
+	return min(requested, maxPageLimit)
+}
+
+// toPage expects items fetched with limit+1 rows, the extra row
+// only signals that another page exists.
+func toPage[T any](items []T, limit int, cursor func(T) entities.Cursor) entities.Page[T] {
+
+	page := entities.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := cursor(page.Items[limit-1])
+		page.Next = &next
+	}
+
+	return page
+}
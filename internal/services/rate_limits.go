@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"test-task/internal/entities"
+	"test-task/internal/errors"
+	"test-task/internal/logging"
+	"test-task/internal/metrics"
+	"time"
+)
+
+// RateLimiter takes a token from the bucket of key, creating the bucket
+// with policy on first use.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, policy entities.RateLimitPolicy) (entities.RateLimitDecision, error)
+	Cleanup(ctx context.Context, idle time.Duration) error
+}
+
+type access string
+
+const (
+	readAccess  access = "read"
+	writeAccess access = "write"
+)
+
+func NewRateLimitPolicy(rate float64, burst int) (*entities.RateLimitPolicy, error) {
+
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate must be greater than zero")
+	}
+
+	if burst < 1 {
+		return nil, fmt.Errorf("burst must be at least 1")
+	}
+
+	return &entities.RateLimitPolicy{Rate: rate, Burst: burst}, nil
+}
+
+type WalletRateLimits struct {
+	walletID string
+	read     *entities.RateLimitPolicy
+	write    *entities.RateLimitPolicy
+}
+
+// NewWalletRateLimits overrides the default policies of the wallet, a nil policy restores the default.
+func NewWalletRateLimits(walletID string, read *entities.RateLimitPolicy,
+	write *entities.RateLimitPolicy) (*WalletRateLimits, error) {
+
+	id, err := uuid.Parse(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("walletId is not uuid")
+	}
+
+	return &WalletRateLimits{walletID: id.String(), read: read, write: write}, nil
+}
+
+func (s *WalletsService) SetRateLimits(ctx context.Context, limits WalletRateLimits) (entities.WalletRateLimits, error) {
+
+	overrides := entities.WalletRateLimits{WalletID: limits.walletID}
+	if limits.read != nil {
+		overrides.ReadRate, overrides.ReadBurst = &limits.read.Rate, &limits.read.Burst
+	}
+	if limits.write != nil {
+		overrides.WriteRate, overrides.WriteBurst = &limits.write.Rate, &limits.write.Burst
+	}
+
+	stored, err := s.wallets.SetRateLimits(ctx, overrides)
+	if err != nil {
+		return stored, err
+	}
+
+	s.rateLimitsMu.Lock()
+	s.rateLimits[stored.WalletID] = stored
+	s.rateLimitsMu.Unlock()
+
+	return stored, nil
+}
+
+func (s *WalletsService) ratePolicy(id string, kind access) entities.RateLimitPolicy {
+	s.rateLimitsMu.RLock()
+	overrides := s.rateLimits[id]
+	s.rateLimitsMu.RUnlock()
+
+	if kind == readAccess {
+		return overrides.Read(s.options.ReadRateLimit)
+	}
+	return overrides.Write(s.options.WriteRateLimit)
+}
+
+func (s *WalletsService) allowWalletOperation(ctx context.Context, id string, kind access) error {
+
+	policy := s.ratePolicy(id, kind)
+	decision, err := s.limiter.Allow(ctx, string(kind)+":"+id, policy)
+	if err != nil {
+		return err
+	}
+	if !decision.Allowed {
+		metrics.ObserveRateLimited(string(kind))
+		logging.FromContext(ctx).Debugf("rate limited %s access to wallet %s", kind, id)
+		return &errors.RateLimited{Limit: policy.Burst, RetryAfter: decision.RetryAfter}
+	}
+	return nil
+}
+
+// refreshRateLimits reloads the wallet overrides, picking up changes
+// made through other instances.
+func (s *WalletsService) refreshRateLimits(ctx context.Context) error {
+
+	limits, err := s.wallets.ListRateLimits(ctx)
+	if err != nil {
+		return err
+	}
+
+	rateLimits := make(map[string]entities.WalletRateLimits, len(limits))
+	for _, l := range limits {
+		rateLimits[l.WalletID] = l
+	}
+
+	s.rateLimitsMu.Lock()
+	s.rateLimits = rateLimits
+	s.rateLimitsMu.Unlock()
+	return nil
+}
+
+func (s *WalletsService) limitersCleanup(ctx context.Context) {
+	for {
+		if err := s.refreshRateLimits(ctx); err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Errorf("error refresh rate limits: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.options.RateLimitCleanupInterval):
+			if err := s.limiter.Cleanup(ctx, s.options.RateLimitIdleTTL); err != nil {
+				logging.FromContext(ctx).Errorf("error clean up rate limiters: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"test-task/internal/entities"
+	"test-task/internal/errors"
+	"test-task/internal/money"
+	"test-task/internal/tracing"
+)
+
+type WalletTransfer struct {
+	fromWalletID string
+	toWalletID   string
+	currency     money.Currency
+	amount       decimal.Decimal
+}
+
+func NewWalletTransfer(fromWalletID string, toWalletID string, currency money.Currency,
+	amount decimal.Decimal) (*WalletTransfer, error) {
+
+	from, err := uuid.Parse(fromWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("fromWalletId is not uuid")
+	}
+
+	to, err := uuid.Parse(toWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("toWalletId is not uuid")
+	}
+
+	if from == to {
+		return nil, fmt.Errorf("cannot transfer to the same wallet")
+	}
+
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("transfer amount must be greater than zero")
+	}
+
+	if err := currency.Validate(amount); err != nil {
+		return nil, err
+	}
+
+	return &WalletTransfer{fromWalletID: from.String(), toWalletID: to.String(), currency: currency, amount: amount}, nil
+}
+
+func (s *WalletsService) Transfer(ctx context.Context, transfer WalletTransfer) (_ entities.Transfer, err error) {
+
+	ctx, span := tracing.Start(ctx, "WalletsService.Transfer",
+		tracing.Transfer(transfer.fromWalletID, transfer.toWalletID)...)
+	defer tracing.End(span, &err)
+
+	if err := s.authorizeWallet(ctx, transfer.fromWalletID); err != nil {
+		return entities.Transfer{}, err
+	}
+
+	// only the sender's bucket is charged, anyone may pay into a wallet without
+	// using up its owner's allowance
+	if err := s.allowWalletOperation(ctx, transfer.fromWalletID, writeAccess); err != nil {
+		return entities.Transfer{}, err
+	}
+
+	target, err := s.wallets.GetById(ctx, transfer.toWalletID)
+	if err != nil {
+		return entities.Transfer{}, err
+	}
+
+	result := entities.Transfer{
+		FromWalletID: transfer.fromWalletID,
+		ToWalletID:   transfer.toWalletID,
+		Amount:       transfer.amount,
+		Currency:     transfer.currency.Code,
+		ToAmount:     transfer.amount,
+		ToCurrency:   target.Currency,
+	}
+
+	if target.Currency != transfer.currency.Code {
+		if result.ToAmount, result.FxRate, err = s.convert(ctx, transfer.amount, transfer.currency, target.Currency); err != nil {
+			return entities.Transfer{}, err
+		}
+	}
+
+	return s.wallets.Transfer(ctx, result, s.options.WithdrawalLimits)
+}
+
+func (s *WalletsService) convert(ctx context.Context, amount decimal.Decimal, from money.Currency,
+	to string) (decimal.Decimal, *decimal.Decimal, error) {
+
+	target, err := money.ParseCurrency(to)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+
+	rate, err := s.fxRates.Get(ctx, from.Code, target.Code)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+
+	converted := s.options.FxRounding.Round(amount.Mul(rate.Rate), target.Scale)
+	if !converted.IsPositive() {
+		return decimal.Zero, nil, fmt.Errorf("%w: %s %s converts to zero %s",
+			errors.AmountTooSmall, amount, from.Code, target.Code)
+	}
+
+	return converted, &rate.Rate, nil
+}
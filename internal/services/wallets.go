@@ -2,29 +2,49 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/google/uuid"
-	"golang.org/x/time/rate"
+	"github.com/shopspring/decimal"
 	"sync"
 	"test-task/internal/entities"
 	"test-task/internal/errors"
+	"test-task/internal/logging"
+	"test-task/internal/metrics"
+	"test-task/internal/money"
+	"test-task/internal/tracing"
 	"time"
 )
 
 type operationName string
 
 const (
-	withdraw operationName = "WITHDRAW"
-	deposit  operationName = "DEPOSIT"
+	withdraw operationName = entities.Withdraw
+	deposit  operationName = entities.Deposit
 )
 
+const maxIdempotencyKeyLength = 255
+
+func IsTransactionType(name string) bool {
+	return isWalletOperation(name) || name == entities.TransferOut || name == entities.TransferIn ||
+		name == entities.Capture
+}
+
+func isWalletOperation(name string) bool {
+	return operationName(name) == withdraw || operationName(name) == deposit
+}
+
 type WalletOperation struct {
-	walletID string
-	name     operationName
-	amount   float64
+	walletID       string
+	name           operationName
+	currency       money.Currency
+	amount         decimal.Decimal
+	idempotencyKey string
 }
 
-func NewWalletOperation(walletID string, operation string, amount float64) (*WalletOperation, error) {
+func NewWalletOperation(walletID string, operation string, currency money.Currency,
+	amount decimal.Decimal) (*WalletOperation, error) {
 
 	if walletID == "" {
 		return nil, fmt.Errorf("walletID is empty")
@@ -38,101 +58,252 @@ func NewWalletOperation(walletID string, operation string, amount float64) (*Wal
 		return nil, fmt.Errorf("walletID is not uuid")
 	}
 
-	if operationName(operation) != withdraw && operationName(operation) != deposit {
+	if !isWalletOperation(operation) {
 		return nil, fmt.Errorf("invalid operation name, expected: %s or %s", withdraw, deposit)
 	}
 
-	if amount <= 0 {
+	if !amount.IsPositive() {
 		return nil, fmt.Errorf("operation amount must be greater than zero")
 	}
 
-	return &WalletOperation{walletID, operationName(operation), amount}, nil
+	if err := currency.Validate(amount); err != nil {
+		return nil, err
+	}
+
+	return &WalletOperation{walletID: walletID, name: operationName(operation), currency: currency, amount: amount}, nil
+}
+
+func (o *WalletOperation) WithIdempotencyKey(key string) error {
+
+	if len(key) > maxIdempotencyKeyLength {
+		return fmt.Errorf("idempotency key must be at most %d characters", maxIdempotencyKeyLength)
+	}
+
+	o.idempotencyKey = key
+	return nil
+}
+
+func (o *WalletOperation) idempotency() *entities.IdempotencyKey {
+
+	if o.idempotencyKey == "" {
+		return nil
+	}
+
+	hash := sha256.Sum256([]byte(o.walletID + "|" + string(o.name) + "|" + o.currency.Code + "|" + o.amount.String()))
+	return &entities.IdempotencyKey{Key: o.idempotencyKey, RequestHash: hex.EncodeToString(hash[:])}
 }
 
 type walletsRepository interface {
 	GetById(ctx context.Context, id string) (entities.Wallet, error)
-	ChangeBalance(ctx context.Context, id string, delta float64) error
+	ChangeBalance(ctx context.Context, change entities.BalanceChange) (entities.Transaction, error)
+	ChangeBalances(ctx context.Context, changes []entities.BalanceChange) ([]entities.BalanceChangeResult, error)
+	GetTransactions(ctx context.Context, filter entities.TransactionFilter) ([]entities.Transaction, error)
+	Transfer(ctx context.Context, transfer entities.Transfer,
+		limits entities.CurrencyWithdrawalLimits) (entities.Transfer, error)
+	Create(ctx context.Context, ownerID *string, ownerRef *string, currency string,
+		initialBalance decimal.Decimal) (entities.Wallet, error)
+	List(ctx context.Context, filter entities.WalletFilter) ([]entities.Wallet, error)
+	UpdateStatus(ctx context.Context, id string, status entities.WalletStatus,
+		from []entities.WalletStatus) (entities.Wallet, error)
+	CreateHold(ctx context.Context, hold entities.Hold, limits entities.CurrencyWithdrawalLimits) (entities.Hold, error)
+	GetHold(ctx context.Context, id string) (entities.Hold, error)
+	CaptureHold(ctx context.Context, id string, currency string, amount *decimal.Decimal,
+		limits entities.CurrencyWithdrawalLimits) (entities.Hold, error)
+	VoidHold(ctx context.Context, id string) (entities.Hold, error)
+	ExpireHolds(ctx context.Context, now time.Time) (int64, error)
+	DeleteIdempotencyKeys(ctx context.Context, cutoff time.Time) (int64, error)
+	SetCreditLimit(ctx context.Context, id string, currency string, creditLimit decimal.Decimal) (entities.Wallet, error)
+	SetWithdrawalLimits(ctx context.Context, id string, currency string,
+		limits entities.WithdrawalLimitOverrides) (entities.Wallet, error)
+	SetRateLimits(ctx context.Context, limits entities.WalletRateLimits) (entities.WalletRateLimits, error)
+	ListRateLimits(ctx context.Context) ([]entities.WalletRateLimits, error)
+}
+
+type fxRateProvider interface {
+	Get(ctx context.Context, base string, quote string) (entities.FxRate, error)
 }
 
-type walletLimiter struct {
-	limiter         *rate.Limiter
-	lastRequestTime time.Time
+type Options struct {
+	FxRounding         money.RoundingMode
+	HoldTTL            time.Duration
+	HoldExpiryInterval time.Duration
+	WithdrawalLimits   entities.CurrencyWithdrawalLimits
+
+	// IdempotencyKeyTTL is how long a key is kept for replays, checked every IdempotencyKeyCleanupInterval.
+	IdempotencyKeyTTL             time.Duration
+	IdempotencyKeyCleanupInterval time.Duration
+
+	ReadRateLimit            entities.RateLimitPolicy
+	WriteRateLimit           entities.RateLimitPolicy
+	RateLimitIdleTTL         time.Duration
+	RateLimitCleanupInterval time.Duration
+
+	// WriteCoalescingWindow batches each wallet's operations arriving within it, zero disables batching.
+	WriteCoalescingWindow   time.Duration
+	WriteCoalescingMaxBatch int
 }
 
 type WalletsService struct {
 	wallets       walletsRepository
-	limiters      map[string]*walletLimiter
-	mu            sync.Mutex
+	fxRates       fxRateProvider
+	limiter       RateLimiter
+	options       Options
+	rateLimits    map[string]entities.WalletRateLimits
+	rateLimitsMu  sync.RWMutex
+	coalescer     *coalescer
 	cancelCleanup context.CancelFunc
+	background    sync.WaitGroup
 }
 
-func NewWalletsService(wallets walletsRepository) *WalletsService {
-	service := &WalletsService{wallets: wallets, limiters: make(map[string]*walletLimiter)}
+func NewWalletsService(wallets walletsRepository, fxRates fxRateProvider, limiter RateLimiter,
+	options Options) *WalletsService {
+	service := &WalletsService{
+		wallets:    wallets,
+		fxRates:    fxRates,
+		limiter:    limiter,
+		options:    options,
+		rateLimits: make(map[string]entities.WalletRateLimits),
+	}
+
+	if options.WriteCoalescingWindow > 0 {
+		service.coalescer = newCoalescer(options.WriteCoalescingWindow, options.WriteCoalescingMaxBatch,
+			wallets.ChangeBalances)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	go service.limitersCleanup(ctx)
+	if options.RateLimitCleanupInterval > 0 {
+		service.runInBackground(ctx, service.limitersCleanup)
+	}
+	if options.HoldExpiryInterval > 0 {
+		service.runInBackground(ctx, service.holdsExpiry)
+	}
+	if options.IdempotencyKeyTTL > 0 && options.IdempotencyKeyCleanupInterval > 0 {
+		service.runInBackground(ctx, service.idempotencyKeysCleanup)
+	}
 	service.cancelCleanup = cancel
 	return service
 }
 
-func (s *WalletsService) GetBalance(ctx context.Context, id string) (float64, error) {
+func (s *WalletsService) GetBalance(ctx context.Context, id string) (_ entities.Wallet, err error) {
 
-	if !s.allowWalletOperation(id) {
-		return 0, errors.TooManyRequests
-	}
+	ctx, span := tracing.Start(ctx, "WalletsService.GetBalance", tracing.WalletID(id))
+	defer tracing.End(span, &err)
 
 	wallet, err := s.wallets.GetById(ctx, id)
-	return wallet.Balance, err
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+
+	if err := checkOwner(ctx, wallet); err != nil {
+		return entities.Wallet{}, err
+	}
+
+	if err := s.allowWalletOperation(ctx, id, readAccess); err != nil {
+		return entities.Wallet{}, err
+	}
+
+	return wallet, nil
 }
 
-func (s *WalletsService) RunOperation(ctx context.Context, operation WalletOperation) error {
+func (s *WalletsService) RunOperation(ctx context.Context,
+	operation WalletOperation) (_ entities.Transaction, err error) {
 
-	if !s.allowWalletOperation(operation.walletID) {
-		return errors.TooManyRequests
+	ctx, span := tracing.Start(ctx, "WalletsService.RunOperation",
+		tracing.WalletID(operation.walletID), tracing.Operation(string(operation.name)))
+	defer tracing.End(span, &err)
+	defer func() {
+		metrics.ObserveOperation(string(operation.name), err)
+	}()
+
+	if err := s.authorizeWallet(ctx, operation.walletID); err != nil {
+		return entities.Transaction{}, err
+	}
+
+	if err := s.allowWalletOperation(ctx, operation.walletID, writeAccess); err != nil {
+		return entities.Transaction{}, err
+	}
+
+	change := entities.BalanceChange{
+		WalletID:       operation.walletID,
+		Currency:       operation.currency.Code,
+		OperationType:  string(operation.name),
+		Limits:         s.options.WithdrawalLimits,
+		IdempotencyKey: operation.idempotency(),
 	}
 
 	switch operation.name {
 	case withdraw:
-		return s.wallets.ChangeBalance(ctx, operation.walletID, -operation.amount)
+		change.Delta = operation.amount.Neg()
 	case deposit:
-		return s.wallets.ChangeBalance(ctx, operation.walletID, operation.amount)
+		change.Delta = operation.amount
 	default:
-		return fmt.Errorf("%w: %s", errors.UnsupportedOperation, operation.name)
+		return entities.Transaction{}, fmt.Errorf("%w: %s", errors.UnsupportedOperation, operation.name)
 	}
+
+	if s.coalescer != nil {
+		return s.coalescer.submit(ctx, change)
+	}
+	return s.wallets.ChangeBalance(ctx, change)
 }
 
+func (s *WalletsService) GetTransactions(ctx context.Context,
+	filter entities.TransactionFilter) (_ entities.Page[entities.Transaction], err error) {
+
+	ctx, span := tracing.Start(ctx, "WalletsService.GetTransactions", tracing.WalletID(filter.WalletID))
+	defer tracing.End(span, &err)
+
+	wallet, err := s.wallets.GetById(ctx, filter.WalletID)
+	if err != nil {
+		return entities.Page[entities.Transaction]{}, err
+	}
+
+	if err := checkOwner(ctx, wallet); err != nil {
+		return entities.Page[entities.Transaction]{}, err
+	}
+
+	if err := s.allowWalletOperation(ctx, filter.WalletID, readAccess); err != nil {
+		return entities.Page[entities.Transaction]{}, err
+	}
+
+	limit := pageLimit(filter.Limit)
+	filter.Limit = limit + 1
+
+	transactions, err := s.wallets.GetTransactions(ctx, filter)
+	if err != nil {
+		return entities.Page[entities.Transaction]{}, err
+	}
+
+	return toPage(transactions, limit, func(t entities.Transaction) entities.Cursor {
+		return entities.Cursor{Seq: t.Seq}
+	}), nil
+}
+
+// Close stops the background jobs and waits for a run in progress to finish.
 func (s *WalletsService) Close() {
 	s.cancelCleanup()
+	s.background.Wait()
 }
 
-func (s *WalletsService) allowWalletOperation(id string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	limiter, ok := s.limiters[id]
-	if !ok {
-		limiter = &walletLimiter{
-			limiter:         rate.NewLimiter(1000, 5),
-			lastRequestTime: time.Now(),
-		}
-		s.limiters[id] = limiter
-	}
-	return limiter.limiter.Allow()
+func (s *WalletsService) runInBackground(ctx context.Context, job func(ctx context.Context)) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		job(ctx)
+	}()
 }
 
-func (s *WalletsService) limitersCleanup(ctx context.Context) {
+func (s *WalletsService) idempotencyKeysCleanup(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(1 * time.Minute):
-			s.mu.Lock()
-			for id, limiter := range s.limiters {
-				if time.Since(limiter.lastRequestTime) > 5*time.Minute {
-					delete(s.limiters, id)
-				}
+		case <-time.After(s.options.IdempotencyKeyCleanupInterval):
+			deleted, err := s.wallets.DeleteIdempotencyKeys(ctx, time.Now().Add(-s.options.IdempotencyKeyTTL))
+			if err != nil {
+				logging.FromContext(ctx).Errorf("error delete idempotency keys: %v", err)
+			} else if deleted > 0 {
+				logging.FromContext(ctx).Infof("deleted %d expired idempotency keys", deleted)
 			}
-			s.mu.Unlock()
 		}
 	}
 }
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	walletIDKey     = attribute.Key("wallet.id")
+	operationKey    = attribute.Key("wallet.operation")
+	fromWalletIDKey = attribute.Key("wallet.transfer.from_id")
+	toWalletIDKey   = attribute.Key("wallet.transfer.to_id")
+	holdIDKey       = attribute.Key("wallet.hold.id")
+	batchSizeKey    = attribute.Key("wallet.batch.size")
+)
+
+var tracer = otel.Tracer("test-task")
+
+// Setup installs a global tracer provider sending spans to exporter and the
+// W3C trace context propagator. The returned function flushes pending spans.
+func Setup(exporter sdktrace.SpanExporter, serviceName string) (func(context.Context) error, error) {
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start opens a child span of the one carried by ctx.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records *err on span, if any, and ends it. It takes a pointer so it
+// can be deferred before the error is known.
+func End(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// StartLinked opens a root span for work done on behalf of several requests
+// at once, linked to the span each of the origins carries.
+func StartLinked(name string, origins []context.Context,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	links := make([]trace.Link, 0, len(origins))
+	for _, origin := range origins {
+		if spanContext := trace.SpanContextFromContext(origin); spanContext.IsValid() {
+			links = append(links, trace.Link{SpanContext: spanContext})
+		}
+	}
+
+	return tracer.Start(context.Background(), name, trace.WithNewRoot(),
+		trace.WithLinks(links...), trace.WithAttributes(attributes...))
+}
+
+// Annotate adds attributes to the span carried by ctx, such as the server
+// span of the current request.
+func Annotate(ctx context.Context, attributes ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attributes...)
+}
+
+func WalletID(id string) attribute.KeyValue {
+	return walletIDKey.String(id)
+}
+
+func Operation(name string) attribute.KeyValue {
+	return operationKey.String(name)
+}
+
+func Transfer(fromWalletID string, toWalletID string) []attribute.KeyValue {
+	return []attribute.KeyValue{fromWalletIDKey.String(fromWalletID), toWalletIDKey.String(toWalletID)}
+}
+
+func HoldID(id string) attribute.KeyValue {
+	return holdIDKey.String(id)
+}
+
+func BatchSize(size int) attribute.KeyValue {
+	return batchSizeKey.Int(size)
+}
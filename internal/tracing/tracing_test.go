@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sync"
+	"testing"
+)
+
+var (
+	provider    = sdktrace.NewTracerProvider()
+	installOnce sync.Once
+)
+
+// recordSpans records the spans ended during the test. The package tracer
+// binds to the first global provider installed, so tests share one provider
+// and swap the recorder behind it.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	installOnce.Do(func() { otel.SetTracerProvider(provider) })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider.RegisterSpanProcessor(recorder)
+	t.Cleanup(func() { provider.UnregisterSpanProcessor(recorder) })
+	return recorder
+}
+
+func TestEnd_ShouldRecordErrorOnNestedSpan(t *testing.T) {
+
+	recorder := recordSpans(t)
+
+	ctx, parent := Start(context.Background(), "parent", WalletID("wallet"))
+	func() (err error) {
+		_, span := Start(ctx, "child", Operation("WITHDRAW"))
+		defer End(span, &err)
+		return fmt.Errorf("insufficient balance")
+	}()
+	var noErr error
+	End(parent, &noErr)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		child, root := spans[0], spans[1]
+		assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+		assert.Equal(t, codes.Error, child.Status().Code)
+		assert.Equal(t, codes.Unset, root.Status().Code)
+		assert.Contains(t, root.Attributes(), WalletID("wallet"))
+	}
+}
+
+func TestStartLinked_ShouldOpenRootSpanLinkedToOrigins(t *testing.T) {
+
+	recorder := recordSpans(t)
+
+	first, firstSpan := Start(context.Background(), "first")
+	second, secondSpan := Start(context.Background(), "second")
+
+	_, batch := StartLinked("batch", []context.Context{first, second, context.Background()}, BatchSize(2))
+	batch.End()
+	firstSpan.End()
+	secondSpan.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		linked := spans[0]
+		assert.False(t, linked.Parent().IsValid())
+		if assert.Len(t, linked.Links(), 2) {
+			assert.Equal(t, firstSpan.SpanContext(), linked.Links()[0].SpanContext)
+			assert.Equal(t, secondSpan.SpanContext(), linked.Links()[1].SpanContext)
+		}
+	}
+}
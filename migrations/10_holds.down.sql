@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS holds;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_closed_no_holds;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_available_non_negative;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_held_non_negative;
+ALTER TABLE wallets DROP COLUMN IF EXISTS held;
@@ -0,0 +1,23 @@
+ALTER TABLE wallets ADD COLUMN held NUMERIC(20, 4) NOT NULL DEFAULT 0;
+
+ALTER TABLE wallets ADD CONSTRAINT check_held_non_negative CHECK (held >= 0);
+ALTER TABLE wallets ADD CONSTRAINT check_available_non_negative CHECK (balance - held >= 0);
+ALTER TABLE wallets ADD CONSTRAINT check_closed_no_holds CHECK (status <> 'closed' OR held = 0);
+
+CREATE TABLE holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets (id),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20, 4) NOT NULL,
+    captured_amount NUMERIC(20, 4),
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    transaction_id UUID REFERENCES wallet_transactions (id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT check_hold_amount_positive CHECK (amount > 0),
+    CONSTRAINT check_hold_status CHECK (status IN ('active', 'captured', 'voided', 'expired'))
+);
+
+CREATE INDEX idx_holds_wallet ON holds (wallet_id);
+CREATE INDEX idx_holds_active_expiry ON holds (expires_at) WHERE status = 'active';
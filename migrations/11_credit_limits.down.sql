@@ -0,0 +1,8 @@
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_available_within_credit_limit;
+ALTER TABLE wallets ADD CONSTRAINT check_available_non_negative CHECK (balance - held >= 0);
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_balance_within_credit_limit;
+ALTER TABLE wallets ADD CONSTRAINT check_balance_non_negative CHECK (balance >= 0);
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_credit_limit_non_negative;
+ALTER TABLE wallets DROP COLUMN IF EXISTS credit_limit;
@@ -0,0 +1,8 @@
+ALTER TABLE wallets ADD COLUMN credit_limit NUMERIC(20, 4) NOT NULL DEFAULT 0;
+ALTER TABLE wallets ADD CONSTRAINT check_credit_limit_non_negative CHECK (credit_limit >= 0);
+
+ALTER TABLE wallets DROP CONSTRAINT check_balance_non_negative;
+ALTER TABLE wallets ADD CONSTRAINT check_balance_within_credit_limit CHECK (balance >= -credit_limit);
+
+ALTER TABLE wallets DROP CONSTRAINT check_available_non_negative;
+ALTER TABLE wallets ADD CONSTRAINT check_available_within_credit_limit CHECK (balance - held >= -credit_limit);
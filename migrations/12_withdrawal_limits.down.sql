@@ -0,0 +1,5 @@
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_withdrawal_limits_non_negative;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS monthly_withdrawal_limit;
+ALTER TABLE wallets DROP COLUMN IF EXISTS daily_withdrawal_limit;
+ALTER TABLE wallets DROP COLUMN IF EXISTS per_operation_withdrawal_limit;
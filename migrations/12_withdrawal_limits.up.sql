@@ -0,0 +1,7 @@
+ALTER TABLE wallets ADD COLUMN per_operation_withdrawal_limit NUMERIC(20, 4);
+ALTER TABLE wallets ADD COLUMN daily_withdrawal_limit NUMERIC(20, 4);
+ALTER TABLE wallets ADD COLUMN monthly_withdrawal_limit NUMERIC(20, 4);
+
+ALTER TABLE wallets ADD CONSTRAINT check_withdrawal_limits_non_negative CHECK (
+    per_operation_withdrawal_limit >= 0 AND daily_withdrawal_limit >= 0 AND monthly_withdrawal_limit >= 0
+);
@@ -0,0 +1,9 @@
+CREATE TABLE wallet_rate_limits (
+    wallet_id UUID PRIMARY KEY REFERENCES wallets (id),
+    read_rate DOUBLE PRECISION,
+    read_burst INTEGER,
+    write_rate DOUBLE PRECISION,
+    write_burst INTEGER,
+    CONSTRAINT check_read_policy CHECK ((read_rate IS NULL) = (read_burst IS NULL) AND read_rate > 0 AND read_burst > 0),
+    CONSTRAINT check_write_policy CHECK ((write_rate IS NULL) = (write_burst IS NULL) AND write_rate > 0 AND write_burst > 0)
+);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS wallet_changed ON wallets;
+DROP FUNCTION IF EXISTS notify_wallet_changed();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_wallets_owner_id;
+ALTER TABLE wallets DROP COLUMN IF EXISTS owner_id;
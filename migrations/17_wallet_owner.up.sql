@@ -0,0 +1,3 @@
+ALTER TABLE wallets ADD COLUMN owner_id VARCHAR(255);
+
+CREATE INDEX idx_wallets_owner_id ON wallets (owner_id);
@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN admin BOOLEAN NOT NULL DEFAULT false;
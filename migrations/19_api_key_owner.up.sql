@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN owner_id VARCHAR(255);
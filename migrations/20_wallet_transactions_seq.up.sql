@@ -0,0 +1,14 @@
+ALTER TABLE wallet_transactions ADD COLUMN seq BIGINT;
+
+UPDATE wallet_transactions t SET seq = ordered.seq
+FROM (SELECT id, row_number() OVER (ORDER BY created_at, id) AS seq FROM wallet_transactions) ordered
+WHERE t.id = ordered.id;
+
+CREATE SEQUENCE wallet_transactions_seq_seq AS BIGINT OWNED BY wallet_transactions.seq;
+SELECT setval('wallet_transactions_seq_seq', COALESCE((SELECT MAX(seq) FROM wallet_transactions), 0) + 1, false);
+
+ALTER TABLE wallet_transactions
+    ALTER COLUMN seq SET DEFAULT nextval('wallet_transactions_seq_seq'),
+    ALTER COLUMN seq SET NOT NULL;
+
+CREATE INDEX idx_wallet_transactions_wallet_seq ON wallet_transactions (wallet_id, seq DESC);
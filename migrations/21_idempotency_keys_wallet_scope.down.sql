@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_created;
+
+DELETE FROM idempotency_keys a
+USING idempotency_keys b
+WHERE a.key = b.key AND (a.created_at, a.wallet_id) > (b.created_at, b.wallet_id);
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey,
+    ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key),
+    DROP COLUMN wallet_id;
@@ -0,0 +1,12 @@
+ALTER TABLE idempotency_keys ADD COLUMN wallet_id UUID REFERENCES wallets (id);
+
+UPDATE idempotency_keys k SET wallet_id = t.wallet_id
+FROM wallet_transactions t
+WHERE t.id = k.transaction_id;
+
+ALTER TABLE idempotency_keys
+    ALTER COLUMN wallet_id SET NOT NULL,
+    DROP CONSTRAINT idempotency_keys_pkey,
+    ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (wallet_id, key);
+
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys (created_at);
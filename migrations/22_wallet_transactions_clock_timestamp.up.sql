@@ -0,0 +1 @@
+ALTER TABLE wallet_transactions ALTER COLUMN created_at SET DEFAULT clock_timestamp();
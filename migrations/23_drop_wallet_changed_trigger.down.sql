@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION notify_wallet_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_changed', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallet_changed
+    AFTER UPDATE ON wallets
+    FOR EACH ROW
+    WHEN (OLD IS DISTINCT FROM NEW)
+    EXECUTE FUNCTION notify_wallet_changed();
@@ -0,0 +1,3 @@
+ALTER TABLE wallets ALTER COLUMN balance DROP DEFAULT;
+ALTER TABLE wallets ALTER COLUMN balance TYPE FLOAT USING balance::float;
+ALTER TABLE wallets ALTER COLUMN balance SET DEFAULT 0.0;
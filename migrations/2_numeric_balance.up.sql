@@ -0,0 +1,3 @@
+ALTER TABLE wallets ALTER COLUMN balance DROP DEFAULT;
+ALTER TABLE wallets ALTER COLUMN balance TYPE NUMERIC(20, 4) USING round(balance::numeric, 4);
+ALTER TABLE wallets ALTER COLUMN balance SET DEFAULT 0;
@@ -0,0 +1,10 @@
+CREATE TABLE wallet_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets (id),
+    operation_type VARCHAR(32) NOT NULL,
+    amount NUMERIC(20, 4) NOT NULL,
+    balance_after NUMERIC(20, 4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_wallet_transactions_wallet_created ON wallet_transactions (wallet_id, created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    from_wallet_id UUID NOT NULL REFERENCES wallets (id),
+    to_wallet_id UUID NOT NULL REFERENCES wallets (id),
+    amount NUMERIC(20, 4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT check_transfer_distinct_wallets CHECK (from_wallet_id <> to_wallet_id)
+);
+
+ALTER TABLE wallet_transactions ADD COLUMN transfer_id UUID REFERENCES transfers (id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_wallets_owner_ref;
+DROP INDEX IF EXISTS idx_wallets_created;
+ALTER TABLE wallets DROP COLUMN IF EXISTS created_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS owner_ref;
@@ -0,0 +1,5 @@
+ALTER TABLE wallets ADD COLUMN owner_ref VARCHAR(255);
+ALTER TABLE wallets ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_wallets_created ON wallets (created_at DESC, id DESC);
+CREATE INDEX idx_wallets_owner_ref ON wallets (owner_ref);
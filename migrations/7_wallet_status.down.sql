@@ -0,0 +1,3 @@
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_closed_balance_zero;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS check_wallet_status;
+ALTER TABLE wallets DROP COLUMN IF EXISTS status;
@@ -0,0 +1,4 @@
+ALTER TABLE wallets ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+
+ALTER TABLE wallets ADD CONSTRAINT check_wallet_status CHECK (status IN ('active', 'frozen', 'closed'));
+ALTER TABLE wallets ADD CONSTRAINT check_closed_balance_zero CHECK (status <> 'closed' OR balance = 0);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_wallets_currency;
+ALTER TABLE transfers DROP COLUMN IF EXISTS currency;
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE wallets DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,12 @@
+ALTER TABLE wallets ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE wallets ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE wallet_transactions ADD COLUMN currency CHAR(3);
+UPDATE wallet_transactions t SET currency = w.currency FROM wallets w WHERE w.id = t.wallet_id;
+ALTER TABLE wallet_transactions ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE transfers ADD COLUMN currency CHAR(3);
+UPDATE transfers t SET currency = w.currency FROM wallets w WHERE w.id = t.from_wallet_id;
+ALTER TABLE transfers ALTER COLUMN currency SET NOT NULL;
+
+CREATE INDEX idx_wallets_currency ON wallets (currency);
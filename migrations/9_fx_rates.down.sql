@@ -0,0 +1,5 @@
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS fx_rate;
+ALTER TABLE transfers DROP COLUMN IF EXISTS fx_rate;
+ALTER TABLE transfers DROP COLUMN IF EXISTS to_currency;
+ALTER TABLE transfers DROP COLUMN IF EXISTS to_amount;
+DROP TABLE IF EXISTS fx_rates;
@@ -0,0 +1,17 @@
+CREATE TABLE fx_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base_currency, quote_currency),
+    CONSTRAINT check_fx_rate_positive CHECK (rate > 0)
+);
+
+ALTER TABLE transfers ADD COLUMN to_amount NUMERIC(20, 4);
+ALTER TABLE transfers ADD COLUMN to_currency CHAR(3);
+ALTER TABLE transfers ADD COLUMN fx_rate NUMERIC(20, 10);
+UPDATE transfers SET to_amount = amount, to_currency = currency;
+ALTER TABLE transfers ALTER COLUMN to_amount SET NOT NULL;
+ALTER TABLE transfers ALTER COLUMN to_currency SET NOT NULL;
+
+ALTER TABLE wallet_transactions ADD COLUMN fx_rate NUMERIC(20, 10);
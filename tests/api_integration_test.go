@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/time/rate"
@@ -14,7 +17,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"test-task/internal/config"
 	"test-task/internal/dto"
+	"test-task/internal/entities"
+	errs "test-task/internal/errors"
+	"test-task/internal/money"
+	"test-task/internal/repositories"
 	"testing"
 	"time"
 )
@@ -45,7 +53,7 @@ func TestGetBalance_WhenWalletExists_ShouldReturn200(t *testing.T) {
 	balance, err := getBalance(ginEngine, walletId)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 555.5, balance)
+	assert.True(t, decimal.RequireFromString("555.5").Equal(balance))
 }
 
 func TestOperation_WhenInvalidOperation_ShouldReturn400(t *testing.T) {
@@ -53,7 +61,8 @@ func TestOperation_WhenInvalidOperation_ShouldReturn400(t *testing.T) {
 	op := dto.WalletOperation{
 		WalledID:      "11111111-1111-1111-1111-111111111111",
 		OperationType: "someRandomOperation",
-		Amount:        15,
+		Amount:        dto.AmountFromString("15"),
+		Currency:      "USD",
 	}
 	body, _ := json.Marshal(op)
 
@@ -70,7 +79,8 @@ func TestOperation_WhenWalletDoesntExist_ShouldReturn404(t *testing.T) {
 	op := dto.WalletOperation{
 		WalledID:      "123e4567-e89b-12d3-a456-426614174000",
 		OperationType: "DEPOSIT",
-		Amount:        15,
+		Amount:        dto.AmountFromString("15"),
+		Currency:      "USD",
 	}
 	body, _ := json.Marshal(op)
 
@@ -87,7 +97,8 @@ func TestOperation_WhenBalanceBecomeLessThanZero_ShouldReturn400(t *testing.T) {
 	op := dto.WalletOperation{
 		WalledID:      "11111111-1111-1111-1111-111111111111",
 		OperationType: "WITHDRAW",
-		Amount:        9999999999,
+		Amount:        dto.AmountFromString("9999999999"),
+		Currency:      "USD",
 	}
 	body, _ := json.Marshal(op)
 
@@ -110,14 +121,15 @@ func TestWithdraw(t *testing.T) {
 	op := dto.WalletOperation{
 		WalledID:      walletId,
 		OperationType: "WITHDRAW",
-		Amount:        10,
+		Amount:        dto.AmountFromString("10"),
+		Currency:      "USD",
 	}
 	err = runOperation(engine, op)
 	assert.NoError(t, err)
 
 	balance, err := getBalance(engine, op.WalledID)
 	assert.NoError(t, err)
-	assert.Equal(t, prevBalance-op.Amount, balance)
+	assert.True(t, prevBalance.Sub(decimal.NewFromInt(10)).Equal(balance))
 }
 
 func TestDeposit(t *testing.T) {
@@ -131,14 +143,1000 @@ func TestDeposit(t *testing.T) {
 	op := dto.WalletOperation{
 		WalledID:      walletId,
 		OperationType: "DEPOSIT",
-		Amount:        10,
+		Amount:        dto.AmountFromString("10"),
+		Currency:      "USD",
 	}
 	err = runOperation(engine, op)
 	assert.NoError(t, err)
 
 	balance, err := getBalance(engine, op.WalledID)
 	assert.NoError(t, err)
-	assert.Equal(t, prevBalance+op.Amount, balance)
+	assert.True(t, prevBalance.Add(decimal.NewFromInt(10)).Equal(balance))
+}
+
+func TestOperation_ShouldReturnTransaction(t *testing.T) {
+
+	walletId := "22222222-2222-2222-2222-222222222222"
+	prevBalance, err := getBalance(ginEngine, walletId)
+	assert.NoError(t, err)
+
+	op := dto.WalletOperation{
+		WalledID:      walletId,
+		OperationType: "DEPOSIT",
+		Amount:        dto.AmountFromString("1.25"),
+		Currency:      "USD",
+	}
+	body, _ := json.Marshal(op)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	ginEngine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var transaction dto.Transaction
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &transaction))
+
+	assert.NotEmpty(t, transaction.ID)
+	assert.Equal(t, walletId, transaction.WalletID)
+	assert.Equal(t, "DEPOSIT", transaction.OperationType)
+	assert.Equal(t, "1.25", transaction.Amount)
+	assert.Equal(t, money.FormatCode(prevBalance.Add(decimal.RequireFromString("1.25")), "USD"), transaction.BalanceAfter)
+}
+
+func TestGetTransactions_ShouldPaginateNewestFirst(t *testing.T) {
+
+	walletId := "22222222-2222-2222-2222-222222222222"
+
+	var created []string
+	for i := 0; i < 3; i++ {
+		op := dto.WalletOperation{WalledID: walletId, OperationType: "DEPOSIT", Amount: dto.AmountFromString("3.17"), Currency: "USD"}
+		transaction, err := runOperationWithResult(ginEngine, op)
+		assert.NoError(t, err)
+		created = append(created, transaction.ID)
+	}
+
+	query := "?operationType=DEPOSIT&minAmount=3.17&maxAmount=3.17&limit=2"
+	firstPage, err := getTransactions(ginEngine, walletId, query)
+	assert.NoError(t, err)
+	assert.Len(t, firstPage.Transactions, 2)
+	assert.Equal(t, created[2], firstPage.Transactions[0].ID)
+	assert.Equal(t, created[1], firstPage.Transactions[1].ID)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	secondPage, err := getTransactions(ginEngine, walletId, query+"&cursor="+firstPage.NextCursor)
+	assert.NoError(t, err)
+	assert.Len(t, secondPage.Transactions, 1)
+	assert.Equal(t, created[0], secondPage.Transactions[0].ID)
+	assert.Empty(t, secondPage.NextCursor)
+}
+
+func TestGetTransactions_ShouldListConcurrentOperationsInAppliedOrder(t *testing.T) {
+
+	t.Setenv("RATE_LIMIT_WRITE_BURST", "100")
+	assertConcurrentDepositsListInAppliedOrder(t, setupRoutesForTests())
+}
+
+func TestGetTransactions_WhenWritesCoalesced_ShouldListBatchInAppliedOrder(t *testing.T) {
+
+	t.Setenv("RATE_LIMIT_WRITE_BURST", "100")
+	t.Setenv("WRITE_COALESCING_WINDOW", "50ms")
+	assertConcurrentDepositsListInAppliedOrder(t, setupRoutesForTests())
+}
+
+func assertConcurrentDepositsListInAppliedOrder(t *testing.T, engine *gin.Engine) {
+
+	wallet, err := createWallet(engine, dto.CreateWalletRequest{Currency: "USD"})
+	assert.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op := dto.WalletOperation{WalledID: wallet.ID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("1"), Currency: "USD"}
+			assert.NoError(t, runOperation(engine, op))
+		}()
+	}
+	wg.Wait()
+
+	var balances []decimal.Decimal
+	var createdAt []time.Time
+	query := "?limit=7"
+	for {
+		page, err := getTransactions(engine, wallet.ID, query)
+		assert.NoError(t, err)
+		for _, transaction := range page.Transactions {
+			balances = append(balances, decimal.RequireFromString(transaction.BalanceAfter))
+			createdAt = append(createdAt, transaction.CreatedAt)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query = "?limit=7&cursor=" + page.NextCursor
+	}
+
+	if assert.Len(t, balances, 20) {
+		for i, balance := range balances {
+			assert.True(t, decimal.NewFromInt(int64(20-i)).Equal(balance), "entry %d has balance %s", i, balance)
+		}
+		for i := 1; i < len(createdAt); i++ {
+			assert.False(t, createdAt[i].After(createdAt[i-1]), "entry %d is newer than entry %d", i, i-1)
+		}
+	}
+}
+
+func TestGetTransactions_WhenInvalidFilter_ShouldReturn400(t *testing.T) {
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/11111111-1111-1111-1111-111111111111/transactions?from=yesterday", nil)
+	w := httptest.NewRecorder()
+
+	ginEngine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOperation_WhenIdempotencyKeyReplayed_ShouldReturnOriginalResult(t *testing.T) {
+
+	walletId := "22222222-2222-2222-2222-222222222222"
+	op := dto.WalletOperation{WalledID: walletId, OperationType: "DEPOSIT", Amount: dto.AmountFromString("7"), Currency: "USD"}
+
+	first := postOperation(ginEngine, op, map[string]string{"Idempotency-Key": "replay-key"})
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	balanceAfterFirst, err := getBalance(ginEngine, walletId)
+	assert.NoError(t, err)
+
+	second := postOperation(ginEngine, op, map[string]string{"Idempotency-Key": "replay-key"})
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+
+	balanceAfterSecond, err := getBalance(ginEngine, walletId)
+	assert.NoError(t, err)
+	assert.True(t, balanceAfterFirst.Equal(balanceAfterSecond))
+}
+
+func TestOperation_WhenIdempotencyKeyReusedWithDifferentBody_ShouldReturn409(t *testing.T) {
+
+	walletId := "22222222-2222-2222-2222-222222222222"
+	headers := map[string]string{"Idempotency-Key": "conflict-key"}
+
+	op := dto.WalletOperation{WalledID: walletId, OperationType: "DEPOSIT", Amount: dto.AmountFromString("7"), Currency: "USD"}
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, op, headers).Code)
+
+	op.Amount = dto.AmountFromString("8")
+	assert.Equal(t, http.StatusConflict, postOperation(ginEngine, op, headers).Code)
+}
+
+func TestOperation_WhenIdempotencyKeyUsedOnAnotherWallet_ShouldApplyIndependently(t *testing.T) {
+
+	headers := map[string]string{"Idempotency-Key": "shared-key"}
+	for _, walletID := range []string{"11111111-1111-1111-1111-111111111111", "22222222-2222-2222-2222-222222222222"} {
+		before, err := getBalance(ginEngine, walletID)
+		assert.NoError(t, err)
+
+		op := dto.WalletOperation{WalledID: walletID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("3"), Currency: "USD"}
+		assert.Equal(t, http.StatusOK, postOperation(ginEngine, op, headers).Code)
+
+		after, err := getBalance(ginEngine, walletID)
+		assert.NoError(t, err)
+		assert.True(t, before.Add(decimal.NewFromInt(3)).Equal(after))
+	}
+}
+
+func TestOperation_WhenIdempotencyKeyReplayedAfterBalanceDrained_ShouldReturnOriginalResult(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	headers := map[string]string{"Idempotency-Key": "drain-key-" + wallet.ID}
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("10"), Currency: "USD"}
+
+	first := postOperation(ginEngine, withdraw, headers)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	second := postOperation(ginEngine, withdraw, headers)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+
+	withdraw.Amount = dto.AmountFromString("20")
+	assert.Equal(t, http.StatusConflict, postOperation(ginEngine, withdraw, headers).Code)
+
+	assert.Equal(t, "0.00", getBalanceResponse(t, ginEngine, wallet.ID).Balance)
+}
+
+func TestTransfer_ShouldMoveFundsBetweenWallets(t *testing.T) {
+
+	engine := setupRoutesForTests()
+
+	fromId := "11111111-1111-1111-1111-111111111111"
+	toId := "22222222-2222-2222-2222-222222222222"
+
+	prevFrom, err := getBalance(engine, fromId)
+	assert.NoError(t, err)
+	prevTo, err := getBalance(engine, toId)
+	assert.NoError(t, err)
+
+	w := postTransfer(engine, dto.TransferRequest{FromWalletID: fromId, ToWalletID: toId, Amount: dto.AmountFromString("5.5"), Currency: "USD"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	from, err := getBalance(engine, fromId)
+	assert.NoError(t, err)
+	to, err := getBalance(engine, toId)
+	assert.NoError(t, err)
+
+	assert.True(t, prevFrom.Sub(decimal.RequireFromString("5.5")).Equal(from))
+	assert.True(t, prevTo.Add(decimal.RequireFromString("5.5")).Equal(to))
+}
+
+func TestTransfer_WhenInsufficientBalance_ShouldNotChangeEitherWallet(t *testing.T) {
+
+	engine := setupRoutesForTests()
+
+	fromId := "11111111-1111-1111-1111-111111111111"
+	toId := "22222222-2222-2222-2222-222222222222"
+
+	prevTo, err := getBalance(engine, toId)
+	assert.NoError(t, err)
+
+	w := postTransfer(engine, dto.TransferRequest{FromWalletID: fromId, ToWalletID: toId, Amount: dto.AmountFromString("9999999999"), Currency: "USD"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	to, err := getBalance(engine, toId)
+	assert.NoError(t, err)
+	assert.True(t, prevTo.Equal(to))
+}
+
+func TestTransfer_ConcurrentOppositeDirections_ShouldNotDeadlock(t *testing.T) {
+
+	engine := setupRoutesForTests()
+
+	first := "11111111-1111-1111-1111-111111111111"
+	second := "22222222-2222-2222-2222-222222222222"
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			request := dto.TransferRequest{FromWalletID: first, ToWalletID: second, Amount: dto.AmountFromString("0.01"), Currency: "USD"}
+			if i%2 == 0 {
+				request.FromWalletID, request.ToWalletID = second, first
+			}
+
+			w := postTransfer(engine, request)
+			assert.NotEqual(t, http.StatusInternalServerError, w.Code)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCreateWallet_ShouldBeRetrievable(t *testing.T) {
+
+	owner := "create-owner"
+	initial := dto.AmountFromString("42.10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, OwnerRef: &owner, Currency: "USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, "42.10", wallet.Balance)
+
+	balance, err := getBalance(ginEngine, wallet.ID)
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("42.10").Equal(balance))
+}
+
+func TestListWallets_ShouldFilterByOwnerAndPaginate(t *testing.T) {
+
+	owner := "list-owner"
+	var created []string
+	for i := 0; i < 3; i++ {
+		wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{OwnerRef: &owner, Currency: "USD"})
+		assert.NoError(t, err)
+		created = append(created, wallet.ID)
+	}
+
+	firstPage, err := listWallets(ginEngine, "?ownerRef="+owner+"&limit=2")
+	assert.NoError(t, err)
+	assert.Len(t, firstPage.Wallets, 2)
+	assert.Equal(t, created[2], firstPage.Wallets[0].ID)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	secondPage, err := listWallets(ginEngine, "?ownerRef="+owner+"&limit=2&cursor="+firstPage.NextCursor)
+	assert.NoError(t, err)
+	assert.Len(t, secondPage.Wallets, 1)
+	assert.Equal(t, created[0], secondPage.Wallets[0].ID)
+	assert.Empty(t, secondPage.NextCursor)
+}
+
+func TestFrozenWallet_ShouldRejectDebitsButAcceptCredits(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, changeWalletStatus(ginEngine, wallet.ID, "frozen").Code)
+
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("1"), Currency: "USD"}
+	assert.Equal(t, http.StatusConflict, postOperation(ginEngine, withdraw, nil).Code)
+
+	deposit := dto.WalletOperation{WalledID: wallet.ID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("1"), Currency: "USD"}
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, deposit, nil).Code)
+
+	assert.Equal(t, http.StatusOK, changeWalletStatus(ginEngine, wallet.ID, "active").Code)
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, withdraw, nil).Code)
+}
+
+func TestClosedWallet_ShouldRejectAllOperations(t *testing.T) {
+
+	initial := dto.AmountFromString("1")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, changeWalletStatus(ginEngine, wallet.ID, "closed").Code)
+
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("1"), Currency: "USD"}
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, withdraw, nil).Code)
+	assert.Equal(t, http.StatusOK, changeWalletStatus(ginEngine, wallet.ID, "closed").Code)
+
+	deposit := dto.WalletOperation{WalledID: wallet.ID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("1"), Currency: "USD"}
+	assert.Equal(t, http.StatusConflict, postOperation(ginEngine, deposit, nil).Code)
+	assert.Equal(t, http.StatusConflict, changeWalletStatus(ginEngine, wallet.ID, "active").Code)
+}
+
+func TestOperation_WhenCurrencyMismatch_ShouldReturn400(t *testing.T) {
+
+	op := dto.WalletOperation{
+		WalledID:      "11111111-1111-1111-1111-111111111111",
+		OperationType: "DEPOSIT",
+		Amount:        dto.AmountFromString("1"),
+		Currency:      "EUR",
+	}
+
+	assert.Equal(t, http.StatusBadRequest, postOperation(ginEngine, op, nil).Code)
+}
+
+func TestOperation_WhenAmountExceedsCurrencyPrecision_ShouldReturn400(t *testing.T) {
+
+	initial := dto.AmountFromString("1000")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "JPY"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", wallet.Balance)
+
+	op := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("0.5"), Currency: "JPY"}
+	assert.Equal(t, http.StatusBadRequest, postOperation(ginEngine, op, nil).Code)
+
+	op.Amount = dto.AmountFromMinorUnits(5)
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, op, nil).Code)
+}
+
+func TestTransfer_CrossCurrency_ShouldConvertAndRecordRate(t *testing.T) {
+
+	initial := dto.AmountFromString("100")
+	source, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+	target, err := createWallet(ginEngine, dto.CreateWalletRequest{Currency: "EUR"})
+	assert.NoError(t, err)
+
+	rate := dto.FxRateRequest{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "0.9135"}
+	assert.Equal(t, http.StatusOK, upsertFxRate(ginEngine, rate).Code)
+
+	w := postTransfer(ginEngine, dto.TransferRequest{
+		FromWalletID: source.ID,
+		ToWalletID:   target.ID,
+		Amount:       dto.AmountFromString("10.05"),
+		Currency:     "USD",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var transfer dto.Transfer
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &transfer))
+	assert.Equal(t, "9.18", transfer.ToAmount)
+	assert.Equal(t, "EUR", transfer.ToCurrency)
+	assert.Equal(t, "0.9135", *transfer.FxRate)
+
+	balance, err := getBalance(ginEngine, target.ID)
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("9.18").Equal(balance))
+
+	history, err := getTransactions(ginEngine, source.ID, "?operationType=TRANSFER_OUT")
+	assert.NoError(t, err)
+	assert.Len(t, history.Transactions, 1)
+	assert.Equal(t, "0.9135", *history.Transactions[0].FxRate)
+}
+
+func TestTransfer_WhenFxRateMissing_ShouldReturn404(t *testing.T) {
+
+	initial := dto.AmountFromString("100")
+	source, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "GBP"})
+	assert.NoError(t, err)
+	target, err := createWallet(ginEngine, dto.CreateWalletRequest{Currency: "KWD"})
+	assert.NoError(t, err)
+
+	w := postTransfer(ginEngine, dto.TransferRequest{
+		FromWalletID: source.ID,
+		ToWalletID:   target.ID,
+		Amount:       dto.AmountFromString("1"),
+		Currency:     "GBP",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHold_ShouldReserveFundsUntilCaptured(t *testing.T) {
+
+	initial := dto.AmountFromString("100")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	w := postJSON(ginEngine, "/api/v1/holds", dto.HoldRequest{
+		WalletID: wallet.ID, Amount: dto.AmountFromString("60"), Currency: "USD",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var hold dto.Hold
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hold))
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "100.00", balance.Balance)
+	assert.Equal(t, "40.00", balance.Available)
+
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("50"), Currency: "USD"}
+	assert.Equal(t, http.StatusBadRequest, postOperation(ginEngine, withdraw, nil).Code)
+
+	capture := dto.CaptureRequest{Amount: ptr(dto.AmountFromString("45")), Currency: "USD"}
+	w = postJSON(ginEngine, "/api/v1/holds/"+hold.ID+"/capture", capture)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	balance = getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "55.00", balance.Balance)
+	assert.Equal(t, "55.00", balance.Available)
+
+	w = postJSON(ginEngine, "/api/v1/holds/"+hold.ID+"/void", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestHold_WhenVoided_ShouldReleaseFunds(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	w := postJSON(ginEngine, "/api/v1/holds", dto.HoldRequest{
+		WalletID: wallet.ID, Amount: dto.AmountFromString("10"), Currency: "USD",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var hold dto.Hold
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hold))
+
+	assert.Equal(t, "0.00", getBalanceResponse(t, ginEngine, wallet.ID).Available)
+
+	w = postJSON(ginEngine, "/api/v1/holds/"+hold.ID+"/void", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, "10.00", getBalanceResponse(t, ginEngine, wallet.ID).Available)
+}
+
+func TestHold_WhenExpired_ShouldNotBeCapturable(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	w := postJSON(ginEngine, "/api/v1/holds", dto.HoldRequest{
+		WalletID: wallet.ID, Amount: dto.AmountFromString("5"), Currency: "USD", TtlSeconds: 1,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var hold dto.Hold
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hold))
+
+	time.Sleep(1100 * time.Millisecond)
+
+	w = postJSON(ginEngine, "/api/v1/holds/"+hold.ID+"/capture", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestCreditLimit_ShouldAllowOverdraftUpToLimit(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	w := putJSON(ginEngine, "/api/v1/admin/wallets/"+wallet.ID+"/credit-limit",
+		dto.CreditLimitRequest{CreditLimit: dto.AmountFromString("50"), Currency: "USD"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("60"), Currency: "USD"}
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, withdraw, nil).Code)
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "-50.00", balance.Balance)
+	assert.Equal(t, "0.00", balance.Available)
+
+	withdraw.Amount = dto.AmountFromString("0.01")
+	assert.Equal(t, http.StatusBadRequest, postOperation(ginEngine, withdraw, nil).Code)
+
+	w = putJSON(ginEngine, "/api/v1/admin/wallets/"+wallet.ID+"/credit-limit",
+		dto.CreditLimitRequest{CreditLimit: dto.AmountFromString("20"), Currency: "USD"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWithdrawalLimits_ShouldRejectOverLimitWithdrawals(t *testing.T) {
+
+	initial := dto.AmountFromString("100")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	perOperation, daily := dto.AmountFromString("30"), dto.AmountFromString("50")
+	w := putJSON(ginEngine, "/api/v1/admin/wallets/"+wallet.ID+"/withdrawal-limits",
+		dto.WithdrawalLimitsRequest{PerOperation: &perOperation, Daily: &daily, Currency: "USD"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	withdraw := dto.WalletOperation{WalledID: wallet.ID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("40"), Currency: "USD"}
+	w = postOperation(ginEngine, withdraw, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response dto.LimitExceededResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "withdrawal_limit_exceeded", response.Code)
+	assert.Equal(t, "per_operation", response.Limit)
+	assert.Nil(t, response.ResetAt)
+
+	withdraw.Amount = dto.AmountFromString("30")
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, withdraw, nil).Code)
+
+	withdraw.Amount = dto.AmountFromString("25")
+	w = postOperation(ginEngine, withdraw, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "daily", response.Limit)
+	if assert.NotNil(t, response.ResetAt) {
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), *response.ResetAt, time.Minute)
+	}
+
+	withdraw.Amount = dto.AmountFromString("20")
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, withdraw, nil).Code)
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "50.00", balance.Balance)
+}
+
+func TestWithdrawalLimits_ShouldApplyToHoldsAndCaptures(t *testing.T) {
+
+	initial := dto.AmountFromString("100")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	perOperation, daily := dto.AmountFromString("50"), dto.AmountFromString("60")
+	w := putJSON(ginEngine, "/api/v1/admin/wallets/"+wallet.ID+"/withdrawal-limits",
+		dto.WithdrawalLimitsRequest{PerOperation: &perOperation, Daily: &daily, Currency: "USD"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = postJSON(ginEngine, "/api/v1/holds", dto.HoldRequest{WalletID: wallet.ID, Amount: dto.AmountFromString("55"), Currency: "USD"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	holds := make([]dto.Hold, 2)
+	for i := range holds {
+		w = postJSON(ginEngine, "/api/v1/holds", dto.HoldRequest{WalletID: wallet.ID, Amount: dto.AmountFromString("40"), Currency: "USD"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &holds[i]))
+	}
+
+	assert.Equal(t, http.StatusOK, postJSON(ginEngine, "/api/v1/holds/"+holds[0].ID+"/capture", nil).Code)
+
+	w = postJSON(ginEngine, "/api/v1/holds/"+holds[1].ID+"/capture", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response dto.LimitExceededResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "daily", response.Limit)
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "60.00", balance.Balance)
+	assert.Equal(t, "20.00", balance.Available)
+}
+
+func TestPostgresRateLimiter_ShouldShareBucketsAcrossInstances(t *testing.T) {
+
+	dbContext, err := repositories.NewDbContext(config.Get().DbConnectionString)
+	assert.NoError(t, err)
+	defer dbContext.Close()
+
+	first := repositories.NewRateLimitsRepository(dbContext.DB)
+	second := repositories.NewRateLimitsRepository(dbContext.DB)
+	key := uuid.NewString()
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		instance := first
+		if i%2 == 1 {
+			instance = second
+		}
+		decision, err := instance.Allow(context.Background(), key, entities.RateLimitPolicy{Rate: 1.0 / 3600, Burst: 5})
+		assert.NoError(t, err)
+		if decision.Allowed {
+			allowed++
+		}
+	}
+
+	assert.Equal(t, 5, allowed)
+}
+
+func TestRateLimits_WhenWalletOverrideExceeded_ShouldReturn429WithHeaders(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	w := putJSON(ginEngine, "/api/v1/admin/wallets/"+wallet.ID+"/rate-limits",
+		dto.WalletRateLimitsRequest{Write: &dto.RateLimitPolicy{Rate: 0.1, Burst: 2}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	deposit := dto.WalletOperation{WalledID: wallet.ID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("1"), Currency: "USD"}
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, deposit, nil).Code)
+	assert.Equal(t, http.StatusOK, postOperation(ginEngine, deposit, nil).Code)
+
+	w = postOperation(ginEngine, deposit, nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "12.00", balance.Balance)
+}
+
+func TestChangeBalances_ShouldIsolateFailedChangesInBatch(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	dbContext, err := repositories.NewDbContext(config.Get().DbConnectionString)
+	assert.NoError(t, err)
+	defer dbContext.Close()
+
+	change := func(operationType string, delta string) entities.BalanceChange {
+		return entities.BalanceChange{WalletID: wallet.ID, Currency: "USD", OperationType: operationType,
+			Delta: decimal.RequireFromString(delta)}
+	}
+
+	results, err := repositories.NewWalletsRepository(dbContext.DB).ChangeBalances(context.Background(),
+		[]entities.BalanceChange{change("DEPOSIT", "5"), change("WITHDRAW", "-100"), change("WITHDRAW", "-3")})
+	assert.NoError(t, err)
+
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, errs.InsufficientBalance)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, "12", results[2].Transaction.BalanceAfter.String())
+
+	balance := getBalanceResponse(t, ginEngine, wallet.ID)
+	assert.Equal(t, "12.00", balance.Balance)
+}
+
+func TestCachedWallets_ShouldBeInvalidatedByOtherInstances(t *testing.T) {
+
+	initial := dto.AmountFromString("10")
+	wallet, err := createWallet(ginEngine, dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"})
+	assert.NoError(t, err)
+
+	cfg := config.Get()
+	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString)
+	assert.NoError(t, err)
+	defer dbContext.Close()
+
+	reader := repositories.NewCachedWalletsRepository(repositories.NewWalletsRepository(dbContext.DB), time.Hour)
+	writer := repositories.NewCachedWalletsRepository(repositories.NewWalletsRepository(dbContext.DB), time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = reader.Listen(ctx, cfg.DbConnectionString) }()
+
+	cached, err := reader.GetById(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", cached.Balance.String())
+
+	// the listener connects in the background, so keep writing until one notification gets through
+	assert.Eventually(t, func() bool {
+		_, err := writer.ChangeBalance(ctx, entities.BalanceChange{WalletID: wallet.ID, Currency: "USD",
+			OperationType: "DEPOSIT", Delta: decimal.NewFromInt(1)})
+		if err != nil {
+			return false
+		}
+		cached, err := reader.GetById(ctx, wallet.ID)
+		return err == nil && cached.Balance.GreaterThan(decimal.NewFromInt(10))
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestHealthz_ShouldReturn200(t *testing.T) {
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	ginEngine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyz_WhenMigrated_ShouldReturn200(t *testing.T) {
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	ginEngine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.Readiness
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ready", response.Status)
+	if assert.NotNil(t, response.Migrations) {
+		assert.Equal(t, response.Migrations.ExpectedVersion, response.Migrations.Version)
+	}
+	assert.NotNil(t, response.Pool)
+}
+
+func TestMetrics_ShouldCountOperationsByOutcome(t *testing.T) {
+
+	op := dto.WalletOperation{
+		WalledID:      "11111111-1111-1111-1111-111111111111",
+		OperationType: "WITHDRAW",
+		Amount:        dto.AmountFromString("9999999999"),
+		Currency:      "USD",
+	}
+	assert.Error(t, runOperation(ginEngine, op))
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	ginEngine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `wallet_operations_total{operation="WITHDRAW",outcome="insufficient_balance"}`)
+	assert.Contains(t, w.Body.String(), `wallet_http_request_duration_seconds_count{method="POST",route="/api/v1/wallet"`)
+}
+
+func TestRequestID_ShouldPropagateOrAssign(t *testing.T) {
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	req.Header.Set("X-Request-ID", "client-request-1")
+	w := httptest.NewRecorder()
+	ginEngine.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-request-1", w.Header().Get("X-Request-ID"))
+
+	req, _ = http.NewRequest("GET", "/healthz", nil)
+	req.Header.Set("X-Request-ID", "not a valid id\n")
+	w = httptest.NewRecorder()
+	ginEngine.ServeHTTP(w, req)
+
+	_, err := uuid.Parse(w.Header().Get("X-Request-ID"))
+	assert.NoError(t, err)
+}
+
+func TestApiKeys_ShouldEnforceScopesUntilRevoked(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_ADMIN_API_KEY", bootstrapAdminKey)
+	engine := setupRoutesForTests()
+
+	apiKey := issueApiKey(t, engine, dto.ApiKeyRequest{
+		Name: "reader", Scopes: []string{"read", "deposit"}, OwnerID: ptr("scoped-owner"),
+	})
+
+	send := func(method string, path string, body any, key string) int {
+		return sendWithApiKey(engine, method, path, body, key).Code
+	}
+
+	w := sendWithApiKey(engine, "POST", "/api/v1/wallets", dto.CreateWalletRequest{Currency: "USD"}, apiKey.Key)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var wallet dto.Wallet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wallet))
+
+	walletPath := "/api/v1/wallets/" + wallet.ID
+	operation := func(operationType string) dto.WalletOperation {
+		return dto.WalletOperation{
+			WalledID:      wallet.ID,
+			OperationType: operationType,
+			Amount:        dto.AmountFromString("1"),
+			Currency:      "USD",
+		}
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("GET", walletPath, nil, ""))
+	assert.Equal(t, http.StatusUnauthorized, send("GET", walletPath, nil, "wk_unknown"))
+	assert.Equal(t, http.StatusOK, send("GET", walletPath, nil, apiKey.Key))
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/wallet", operation("DEPOSIT"), apiKey.Key))
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/v1/wallet", operation("WITHDRAW"), apiKey.Key))
+
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/admin/api-keys/"+apiKey.ID+"/revoke", nil, bootstrapAdminKey))
+	assert.Equal(t, http.StatusUnauthorized, send("GET", walletPath, nil, apiKey.Key))
+}
+
+func TestOperation_WhenBodyTooLargeWithAuth_ShouldReturn413(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_ADMIN_API_KEY", bootstrapAdminKey)
+	engine := setupRoutesForTests()
+
+	body := map[string]string{"operationType": "DEPOSIT", "padding": strings.Repeat("a", 100<<10)}
+	w := sendWithApiKey(engine, "POST", "/api/v1/wallet", body, bootstrapAdminKey)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestApiKeys_ShouldOnlyActOnTheirOwnersWallets(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_ADMIN_API_KEY", bootstrapAdminKey)
+	engine := setupRoutesForTests()
+
+	scopes := []string{"read", "deposit", "withdraw"}
+	carol := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "carol", Scopes: scopes, OwnerID: ptr("carol")})
+	dave := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "dave", Scopes: scopes, OwnerID: ptr("dave")})
+	admin := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "ops", Scopes: scopes, Admin: true})
+
+	w := postJSON(engine, "/api/v1/admin/api-keys", dto.ApiKeyRequest{Name: "unbound", Scopes: scopes})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendWithApiKey(engine, "POST", "/api/v1/admin/api-keys", dto.ApiKeyRequest{Name: "unbound", Scopes: scopes}, bootstrapAdminKey)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendWithApiKey(engine, "POST", "/api/v1/wallets", dto.CreateWalletRequest{Currency: "USD"}, carol.Key)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var wallet dto.Wallet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wallet))
+	if assert.NotNil(t, wallet.OwnerID) {
+		assert.Equal(t, "carol", *wallet.OwnerID)
+	}
+
+	walletPath := "/api/v1/wallets/" + wallet.ID
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "GET", walletPath, nil, carol.Key).Code)
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "GET", walletPath, nil, admin.Key).Code)
+	assert.Equal(t, http.StatusForbidden, sendWithApiKey(engine, "GET", walletPath, nil, dave.Key).Code)
+	assert.Equal(t, http.StatusForbidden,
+		sendWithApiKey(engine, "GET", "/api/v1/wallets/11111111-1111-1111-1111-111111111111", nil, carol.Key).Code)
+
+	var page dto.WalletPage
+	w = sendWithApiKey(engine, "GET", "/api/v1/wallets", nil, dave.Key)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	for _, listed := range page.Wallets {
+		assert.NotEqual(t, wallet.ID, listed.ID)
+	}
+}
+
+func TestRateLimits_ShouldNotChargeOwnerForOthersRequests(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_ADMIN_API_KEY", bootstrapAdminKey)
+	engine := setupRoutesForTests()
+
+	scopes := []string{"read", "deposit", "withdraw"}
+	erin := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "erin", Scopes: scopes, OwnerID: ptr("erin")})
+	frank := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "frank", Scopes: scopes, OwnerID: ptr("frank")})
+
+	createOwned := func(key string) dto.Wallet {
+		initial := dto.AmountFromString("100")
+		w := sendWithApiKey(engine, "POST", "/api/v1/wallets",
+			dto.CreateWalletRequest{InitialBalance: &initial, Currency: "USD"}, key)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var wallet dto.Wallet
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wallet))
+		return wallet
+	}
+	erinWallet := createOwned(erin.Key)
+	frankWallet := createOwned(frank.Key)
+
+	deposit := dto.WalletOperation{WalledID: erinWallet.ID, OperationType: "DEPOSIT",
+		Amount: dto.AmountFromString("1"), Currency: "USD"}
+	transfer := dto.TransferRequest{FromWalletID: frankWallet.ID, ToWalletID: erinWallet.ID,
+		Amount: dto.AmountFromString("1"), Currency: "USD"}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusForbidden, sendWithApiKey(engine, "POST", "/api/v1/wallet", deposit, frank.Key).Code)
+		assert.Equal(t, http.StatusForbidden,
+			sendWithApiKey(engine, "GET", "/api/v1/wallets/"+erinWallet.ID, nil, frank.Key).Code)
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "POST", "/api/v1/transfers", transfer, frank.Key).Code)
+	}
+
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "POST", "/api/v1/wallet", deposit, erin.Key).Code)
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "GET", "/api/v1/wallets/"+erinWallet.ID, nil, erin.Key).Code)
+}
+
+func TestAdminRoutes_ShouldRequireAdmin(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_ADMIN_API_KEY", bootstrapAdminKey)
+	t.Setenv("AUTH_JWT_HMAC_SECRET", "integration-secret")
+	engine := setupRoutesForTests()
+
+	walletKey := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "wallet-only", Scopes: entities.Scopes, OwnerID: ptr("alice")})
+	adminKey := issueApiKey(t, engine, dto.ApiKeyRequest{Name: "ops", Scopes: []string{"read"}, Admin: true})
+
+	token := func(subject string, roles ...string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   subject,
+			"roles": roles,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}).SignedString([]byte("integration-secret"))
+		assert.NoError(t, err)
+		return signed
+	}
+	userToken, adminToken := token("alice"), token("ops", "admin")
+
+	walletPath := "/api/v1/admin/wallets/11111111-1111-1111-1111-111111111111"
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"PUT", walletPath + "/status"},
+		{"PUT", walletPath + "/credit-limit"},
+		{"PUT", walletPath + "/withdrawal-limits"},
+		{"PUT", walletPath + "/rate-limits"},
+		{"GET", "/api/v1/admin/fx-rates"},
+		{"PUT", "/api/v1/admin/fx-rates"},
+		{"POST", "/api/v1/admin/api-keys"},
+		{"POST", "/api/v1/admin/api-keys/" + walletKey.ID + "/revoke"},
+	}
+
+	for _, route := range routes {
+		name := route.method + " " + route.path
+
+		assert.Equal(t, http.StatusUnauthorized, sendWithApiKey(engine, route.method, route.path, nil, "").Code, name)
+		assert.Equal(t, http.StatusUnauthorized, sendWithApiKey(engine, route.method, route.path, nil, "wk_unknown").Code, name)
+		assert.Equal(t, http.StatusForbidden, sendWithApiKey(engine, route.method, route.path, nil, walletKey.Key).Code, name)
+		assert.Equal(t, http.StatusForbidden, sendWithBearer(engine, route.method, route.path, nil, userToken).Code, name)
+	}
+
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "GET", "/api/v1/admin/fx-rates", nil, adminKey.Key).Code)
+	assert.Equal(t, http.StatusOK, sendWithBearer(engine, "GET", "/api/v1/admin/fx-rates", nil, adminToken).Code)
+	assert.Equal(t, http.StatusOK, sendWithApiKey(engine, "GET", "/api/v1/admin/fx-rates", nil, bootstrapAdminKey).Code)
+}
+
+func TestWalletOwnership_ShouldOnlyAllowOwnerOrAdmin(t *testing.T) {
+
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_JWT_HMAC_SECRET", "integration-secret")
+	engine := setupRoutesForTests()
+
+	token := func(subject string, roles ...string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   subject,
+			"roles": roles,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}).SignedString([]byte("integration-secret"))
+		assert.NoError(t, err)
+		return signed
+	}
+
+	send := func(method string, path string, body any, bearer string) *httptest.ResponseRecorder {
+		return sendWithBearer(engine, method, path, body, bearer)
+	}
+
+	alice, bob, admin := token("alice"), token("bob"), token("ops", "admin")
+
+	w := send("POST", "/api/v1/wallets", dto.CreateWalletRequest{Currency: "USD"}, alice)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var wallet dto.Wallet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wallet))
+	if assert.NotNil(t, wallet.OwnerID) {
+		assert.Equal(t, "alice", *wallet.OwnerID)
+	}
+
+	deposit := dto.WalletOperation{
+		WalledID:      wallet.ID,
+		OperationType: "DEPOSIT",
+		Amount:        dto.AmountFromString("10"),
+		Currency:      "USD",
+	}
+
+	assert.Equal(t, http.StatusOK, send("GET", "/api/v1/wallets/"+wallet.ID, nil, alice).Code)
+	assert.Equal(t, http.StatusOK, send("GET", "/api/v1/wallets/"+wallet.ID, nil, admin).Code)
+	assert.Equal(t, http.StatusForbidden, send("GET", "/api/v1/wallets/"+wallet.ID, nil, bob).Code)
+
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/wallet", deposit, alice).Code)
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/wallet", deposit, admin).Code)
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/v1/wallet", deposit, bob).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/v1/wallets/"+wallet.ID, nil, "invalid").Code)
 }
 
 func TestConcurrentWalletOperations(t *testing.T) {
@@ -147,7 +1145,8 @@ func TestConcurrentWalletOperations(t *testing.T) {
 
 	duration := time.Second
 	numRequests := 700
-	depositAmount := 1000.0
+	depositAmount := dto.AmountFromMinorUnits(100000)
+	usd, _ := money.ParseCurrency("USD")
 
 	prevBalance, err := getBalance(ginEngine, walletID)
 	assert.NoError(t, err)
@@ -166,6 +1165,7 @@ func TestConcurrentWalletOperations(t *testing.T) {
 				WalledID:      walletID,
 				OperationType: "DEPOSIT",
 				Amount:        depositAmount,
+				Currency:      "USD",
 			}
 			err := limiter.Wait(context.Background())
 			assert.NoError(t, err)
@@ -181,7 +1181,8 @@ func TestConcurrentWalletOperations(t *testing.T) {
 
 	balance, err := getBalance(ginEngine, walletID)
 	assert.NoError(t, err)
-	assert.Equal(t, prevBalance+float64(numRequests)*depositAmount, balance)
+	expected := prevBalance.Add(depositAmount.Decimal(usd.Scale).Mul(decimal.NewFromInt(int64(numRequests))))
+	assert.True(t, expected.Equal(balance))
 }
 
 func TestConcurrentWalletOperations_WithExceedingRateLimit(t *testing.T) {
@@ -207,10 +1208,10 @@ func TestConcurrentWalletOperations_WithExceedingRateLimit(t *testing.T) {
 
 			r := rand.Int() % 3
 			if r == 0 {
-				op := dto.WalletOperation{WalledID: walletID, OperationType: "DEPOSIT", Amount: 0.01}
+				op := dto.WalletOperation{WalledID: walletID, OperationType: "DEPOSIT", Amount: dto.AmountFromString("0.01"), Currency: "USD"}
 				err = runOperation(ginEngine, op)
 			} else if r == 1 {
-				op := dto.WalletOperation{WalledID: walletID, OperationType: "WITHDRAW", Amount: 0.01}
+				op := dto.WalletOperation{WalledID: walletID, OperationType: "WITHDRAW", Amount: dto.AmountFromString("0.01"), Currency: "USD"}
 				err = runOperation(ginEngine, op)
 			} else if r == 2 {
 				_, err = getBalance(ginEngine, walletID)
@@ -233,22 +1234,22 @@ func TestConcurrentWalletOperations_WithExceedingRateLimit(t *testing.T) {
 	assert.True(t, has429)
 }
 
-func getBalance(gin *gin.Engine, walletID string) (float64, error) {
+func getBalance(gin *gin.Engine, walletID string) (decimal.Decimal, error) {
 	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID, nil)
 	w := httptest.NewRecorder()
 	gin.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", w.Code)
+		return decimal.Zero, fmt.Errorf("unexpected status code: %d", w.Code)
 	}
 
 	var response dto.WalletBalance
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
-	return response.Balance, nil
+	return decimal.NewFromString(response.Balance)
 }
 
 func runOperation(engine *gin.Engine, op dto.WalletOperation) error {
@@ -263,3 +1264,164 @@ func runOperation(engine *gin.Engine, op dto.WalletOperation) error {
 
 	return nil
 }
+
+func runOperationWithResult(engine *gin.Engine, op dto.WalletOperation) (dto.Transaction, error) {
+	body, _ := json.Marshal(op)
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var transaction dto.Transaction
+	if w.Code != http.StatusOK {
+		return transaction, fmt.Errorf("unexpected status code: %d", w.Code)
+	}
+
+	err := json.Unmarshal(w.Body.Bytes(), &transaction)
+	return transaction, err
+}
+
+func getTransactions(engine *gin.Engine, walletID string, query string) (dto.TransactionPage, error) {
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID+"/transactions"+query, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var page dto.TransactionPage
+	if w.Code != http.StatusOK {
+		return page, fmt.Errorf("unexpected status code: %d", w.Code)
+	}
+
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	return page, err
+}
+
+func postOperation(engine *gin.Engine, op dto.WalletOperation, headers map[string]string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(op)
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func postTransfer(engine *gin.Engine, request dto.TransferRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/api/v1/transfers", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func createWallet(engine *gin.Engine, request dto.CreateWalletRequest) (dto.Wallet, error) {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/api/v1/wallets", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var wallet dto.Wallet
+	if w.Code != http.StatusCreated {
+		return wallet, fmt.Errorf("unexpected status code: %d", w.Code)
+	}
+
+	err := json.Unmarshal(w.Body.Bytes(), &wallet)
+	return wallet, err
+}
+
+func listWallets(engine *gin.Engine, query string) (dto.WalletPage, error) {
+	req, _ := http.NewRequest("GET", "/api/v1/wallets"+query, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var page dto.WalletPage
+	if w.Code != http.StatusOK {
+		return page, fmt.Errorf("unexpected status code: %d", w.Code)
+	}
+
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	return page, err
+}
+
+func changeWalletStatus(engine *gin.Engine, walletID string, status string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(dto.WalletStatusChange{Status: status})
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/wallets/"+walletID+"/status", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func upsertFxRate(engine *gin.Engine, request dto.FxRateRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/fx-rates", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func postJSON(engine *gin.Engine, path string, payload any) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if payload != nil {
+		_ = json.NewEncoder(&body).Encode(payload)
+	}
+	req, _ := http.NewRequest("POST", path, &body)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func getBalanceResponse(t *testing.T, engine *gin.Engine, walletID string) dto.WalletBalance {
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.WalletBalance
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+// bootstrapAdminKey is configured as AUTH_ADMIN_API_KEY by tests that enable authentication.
+const bootstrapAdminKey = "integration-bootstrap-admin-key-0123456789"
+
+func issueApiKey(t *testing.T, engine *gin.Engine, request dto.ApiKeyRequest) dto.ApiKey {
+
+	w := sendWithApiKey(engine, "POST", "/api/v1/admin/api-keys", request, bootstrapAdminKey)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var apiKey dto.ApiKey
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiKey))
+	assert.NotEmpty(t, apiKey.Key)
+	return apiKey
+}
+
+func sendWithApiKey(engine *gin.Engine, method string, path string, body any, key string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func sendWithBearer(engine *gin.Engine, method string, path string, body any, token string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func putJSON(engine *gin.Engine, path string, payload any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("PUT", path, bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
@@ -8,11 +8,9 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"os"
+	"test-task/internal/app"
 	"test-task/internal/config"
-	"test-task/internal/handlers"
 	"test-task/internal/repositories"
-	"test-task/internal/router"
-	"test-task/internal/services"
 	"testing"
 	"time"
 )
@@ -26,16 +24,17 @@ func setupRoutesForTests() *gin.Engine {
 	engine := gin.New()
 
 	cfg := config.Get()
-	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString)
+	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString, cfg.DbReplicas...)
 	if err != nil {
 		log.Fatalf("setupRoutesForTests: error create dbContext: %v", err)
 	}
 
-	walletRepository := repositories.NewWalletsRepository(dbContext.DB)
-	walletService := services.NewWalletsService(walletRepository)
-	walletHandler := handlers.NewWalletHandler(walletService)
+	application, err := app.New(cfg, dbContext, engine)
+	if err != nil {
+		log.Fatalf("setupRoutesForTests: error setup app: %v", err)
+	}
+	application.Health.SetReady(true)
 
-	router.Setup(engine, walletHandler)
 	return engine
 }
 
@@ -82,6 +81,11 @@ func upEnvironment() {
 		log.Fatalf("could not set environment variable DB_CONNECTION_STRING: %s", err)
 	}
 
+	// tests exercising authentication turn it back on for their own engine
+	if err := os.Setenv("AUTH_ENABLED", "false"); err != nil {
+		log.Fatalf("could not set environment variable AUTH_ENABLED: %s", err)
+	}
+
 	cfg := config.Get()
 	dbContext, err := repositories.NewDbContext(cfg.DbConnectionString)
 	if err != nil {